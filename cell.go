@@ -6,5 +6,9 @@ type Cell struct {
 	index      int
 	identifier string
 	Key        string
-	Value      interface{}
+	// Value is written as a number, a date or text depending on its type. Unsigned integers larger than 2^53 are written
+	// as text, as Excel can't hold all of them exactly.
+	Value interface{}
+	// Style overrides the style of the column the cell belongs to.
+	Style *Style
 }
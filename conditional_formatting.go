@@ -0,0 +1,300 @@
+package xlsx
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConditionalFormatType is the type of a conditional formatting rule.
+type ConditionalFormatType string
+
+// Conditional formatting rule types.
+const (
+	// ConditionalFormatCellIs compares the cell value with one or two formulas using Operator.
+	ConditionalFormatCellIs ConditionalFormatType = "cellIs"
+	// ConditionalFormatExpression applies Format when the formula evaluates to true.
+	ConditionalFormatExpression ConditionalFormatType = "expression"
+	// ConditionalFormatColorScale shades the cells with a gradient of two or three Colors.
+	ConditionalFormatColorScale ConditionalFormatType = "colorScale"
+	// ConditionalFormatDataBar draws a bar of the first of Colors proportional to the cell value.
+	ConditionalFormatDataBar ConditionalFormatType = "dataBar"
+	// ConditionalFormatIconSet shows an icon from IconSet depending on the cell value.
+	ConditionalFormatIconSet ConditionalFormatType = "iconSet"
+	// ConditionalFormatTop10 applies Format to the top, or bottom, Rank values.
+	ConditionalFormatTop10 ConditionalFormatType = "top10"
+	// ConditionalFormatDuplicateValues applies Format to values that appear more than once.
+	ConditionalFormatDuplicateValues ConditionalFormatType = "duplicateValues"
+)
+
+var cellIsOperands = map[string]int{
+	"lessThan":           1,
+	"lessThanOrEqual":    1,
+	"equal":              1,
+	"notEqual":           1,
+	"greaterThanOrEqual": 1,
+	"greaterThan":        1,
+	"between":            2,
+	"notBetween":         2,
+}
+
+// iconSets are the icon sets Excel knows, with their number of icons.
+var iconSets = map[string]int{
+	"3Arrows":         3,
+	"3ArrowsGray":     3,
+	"3Flags":          3,
+	"3TrafficLights1": 3,
+	"3TrafficLights2": 3,
+	"3Signs":          3,
+	"3Symbols":        3,
+	"3Symbols2":       3,
+	"4Arrows":         4,
+	"4ArrowsGray":     4,
+	"4RedToBlack":     4,
+	"4Rating":         4,
+	"4TrafficLights":  4,
+	"5Arrows":         5,
+	"5ArrowsGray":     5,
+	"5Rating":         5,
+	"5Quarters":       5,
+}
+
+// ConditionalFormatRule is a conditional formatting rule. Only the fields used by its Type are considered.
+type ConditionalFormatRule struct {
+	Type ConditionalFormatType
+	// Operator is used by cellIs rules, one of lessThan, lessThanOrEqual, equal, notEqual, greaterThanOrEqual,
+	// greaterThan, between and notBetween.
+	Operator string
+	// Formulas are the operands of a cellIs rule or the single formula of an expression rule, without a leading =.
+	// Relative references are relative to the top left cell of the range.
+	Formulas []string
+	// Format is applied by cellIs, expression, top10 and duplicateValues rules.
+	Format *DifferentialFormat
+	// Colors are the colors of a color scale, from the lowest to the highest value, or the color of a data bar.
+	Colors []string
+	// IconSet is the name of an icon set such as 3Arrows, 3TrafficLights1 or 5Rating.
+	IconSet string
+	// Rank, Bottom and Percent are used by top10 rules.
+	Rank    int
+	Bottom  bool
+	Percent bool
+	// StopIfTrue stops the evaluation of the rules with a lower priority when this one applies.
+	StopIfTrue bool
+}
+
+type conditionalFormatting struct {
+	sqref     string
+	columnKey string
+	rules     []*conditionalFormatRule
+}
+
+type conditionalFormatRule struct {
+	ruleType   string
+	dxfID      int
+	attrs      string
+	body       string
+	stopIfTrue bool
+}
+
+// AddConditionalFormatting applies conditional formatting rules to a range such as A2:A100, several ranges may be
+// given separated by spaces. Rules are evaluated in the order they are added to the worksheet.
+func (ws *Worksheet) AddConditionalFormatting(ref string, rules ...*ConditionalFormatRule) error {
+	for _, r := range strings.Fields(ref) {
+		var err error
+		if strings.Contains(r, ":") {
+			_, _, _, _, err = parseRange(r)
+		} else {
+			_, _, err = parseIdentifier(r)
+		}
+		if err != nil {
+			return errors.Wrap(err, "can't add conditional formatting")
+		}
+	}
+	if ref == "" {
+		return errors.New("can't add conditional formatting without a range")
+	}
+
+	return ws.addConditionalFormatting(&conditionalFormatting{sqref: strings.ToUpper(ref)}, rules)
+}
+
+// AddColumnConditionalFormatting is just like AddConditionalFormatting but applies the rules to every data row of a
+// column defined with DefineColumns. The range is computed when the worksheet is committed.
+func (ws *Worksheet) AddColumnConditionalFormatting(key string, rules ...*ConditionalFormatRule) error {
+	if ws.columns == nil {
+		return errors.Wrap(ErrNoColumns, "can't add conditional formatting to a column")
+	}
+	if ws.columnIndex(key) < 0 {
+		return errors.Wrapf(ErrUnknownColumn, "can't add conditional formatting to column %s", key)
+	}
+
+	return ws.addConditionalFormatting(&conditionalFormatting{columnKey: key}, rules)
+}
+
+func (ws *Worksheet) addConditionalFormatting(cf *conditionalFormatting, rules []*ConditionalFormatRule) error {
+	if ws.committed {
+		return errors.Wrap(ErrCommitted, "can't add conditional formatting to the worksheet")
+	}
+
+	if len(rules) == 0 {
+		return errors.New("can't add conditional formatting without rules")
+	}
+
+	for i := 0; i < len(rules); i++ {
+		rule, err := ws.workbook.createConditionalFormatRule(rules[i])
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s rule", rules[i].Type)
+		}
+		cf.rules = append(cf.rules, rule)
+	}
+
+	ws.conditionalFormats = append(ws.conditionalFormats, cf)
+
+	return nil
+}
+
+func (wb *Workbook) createConditionalFormatRule(r *ConditionalFormatRule) (*conditionalFormatRule, error) {
+	rule := &conditionalFormatRule{
+		ruleType:   string(r.Type),
+		dxfID:      -1,
+		stopIfTrue: r.StopIfTrue,
+	}
+
+	switch r.Type {
+	case ConditionalFormatCellIs:
+		operands, ok := cellIsOperands[r.Operator]
+		if !ok {
+			return nil, errors.Errorf("unknown operator %q", r.Operator)
+		}
+		if len(r.Formulas) != operands {
+			return nil, errors.Errorf("the %s operator takes %d formulas, found %d", r.Operator, operands, len(r.Formulas))
+		}
+		rule.attrs = fmt.Sprintf(` operator="%s"`, r.Operator)
+		for i := 0; i < len(r.Formulas); i++ {
+			rule.body += formulaFormat(r.Formulas[i])
+		}
+	case ConditionalFormatExpression:
+		if len(r.Formulas) != 1 {
+			return nil, errors.Errorf("an expression takes 1 formula, found %d", len(r.Formulas))
+		}
+		rule.body = formulaFormat(r.Formulas[0])
+	case ConditionalFormatColorScale:
+		colors, err := normalizeColors(r.Colors)
+		if err != nil {
+			return nil, err
+		}
+		switch len(colors) {
+		case 2:
+			rule.body = cfvoFormat("min", "") + cfvoFormat("max", "")
+		case 3:
+			rule.body = cfvoFormat("min", "") + cfvoFormat("percentile", "50") + cfvoFormat("max", "")
+		default:
+			return nil, errors.Errorf("a color scale takes 2 or 3 colors, found %d", len(colors))
+		}
+		for i := 0; i < len(colors); i++ {
+			rule.body += colorFormat(colors[i])
+		}
+		rule.body = "<colorScale>" + rule.body + "</colorScale>"
+	case ConditionalFormatDataBar:
+		colors, err := normalizeColors(r.Colors)
+		if err != nil {
+			return nil, err
+		}
+		if len(colors) != 1 {
+			return nil, errors.Errorf("a data bar takes 1 color, found %d", len(colors))
+		}
+		rule.body = "<dataBar>" + cfvoFormat("min", "") + cfvoFormat("max", "") + colorFormat(colors[0]) + "</dataBar>"
+	case ConditionalFormatIconSet:
+		icons, ok := iconSets[r.IconSet]
+		if !ok {
+			return nil, errors.Errorf("unknown icon set %q", r.IconSet)
+		}
+		body := ""
+		for i := 0; i < icons; i++ {
+			body += cfvoFormat("percent", fmt.Sprint(i*100/icons))
+		}
+		rule.body = fmt.Sprintf(`<iconSet iconSet="%s">%s</iconSet>`, escapeXML(r.IconSet), body)
+	case ConditionalFormatTop10:
+		if r.Rank <= 0 {
+			return nil, errors.New("the rank of a top10 rule must be greater than 0")
+		}
+		rule.attrs = fmt.Sprintf(` rank="%d"`, r.Rank)
+		if r.Bottom {
+			rule.attrs += ` bottom="1"`
+		}
+		if r.Percent {
+			rule.attrs += ` percent="1"`
+		}
+	case ConditionalFormatDuplicateValues:
+	default:
+		return nil, errors.Errorf("unknown conditional format type %q", r.Type)
+	}
+
+	switch r.Type {
+	case ConditionalFormatCellIs, ConditionalFormatExpression, ConditionalFormatTop10, ConditionalFormatDuplicateValues:
+		if r.Format == nil {
+			return nil, errors.New("a format is required")
+		}
+		dxfID, err := wb.styles.addDifferentialFormat(r.Format)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid format")
+		}
+		rule.dxfID = dxfID
+	}
+
+	if rule.stopIfTrue {
+		rule.attrs += ` stopIfTrue="1"`
+	}
+
+	return rule, nil
+}
+
+func normalizeColors(colors []string) ([]string, error) {
+	normalized := make([]string, len(colors))
+	for i := 0; i < len(colors); i++ {
+		color, err := normalizeColor(colors[i])
+		if err != nil {
+			return nil, err
+		}
+		if color == "" {
+			return nil, errors.New("colors can't be empty")
+		}
+		normalized[i] = color
+	}
+	return normalized, nil
+}
+
+func (ws *Worksheet) writeConditionalFormatting(f *os.File) error {
+	priority := 0
+	for i := 0; i < len(ws.conditionalFormats); i++ {
+		cf := ws.conditionalFormats[i]
+
+		sqref := cf.sqref
+		if cf.columnKey != "" {
+			sqref = ws.columnDataRange(cf.columnKey)
+			if sqref == "" {
+				continue
+			}
+		}
+
+		_, err := f.WriteString(startConditionalFormattingFormat(sqref))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append START_CONDITIONAL_FORMATTING to file %s", ws.filePath)
+		}
+		for j := 0; j < len(cf.rules); j++ {
+			priority++
+			r := cf.rules[j]
+			_, err = f.WriteString(cfRuleFormat(r.ruleType, r.dxfID, priority, r.attrs, r.body))
+			if err != nil {
+				return errors.Wrapf(err, "failed to append a conditional formatting rule to file %s", ws.filePath)
+			}
+		}
+		_, err = f.WriteString(endConditionalFmt)
+		if err != nil {
+			return errors.Wrapf(err, "failed to append END_CONDITIONAL_FORMATTING to file %s", ws.filePath)
+		}
+	}
+
+	return nil
+}
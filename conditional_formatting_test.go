@@ -0,0 +1,107 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func Test_Worksheet_AddConditionalFormatting_ShouldWriteRules_WhenGivenValidRules(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-5.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Balances",
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "account", Value: "Account"},
+		&WorksheetColumn{Key: "balance", Value: "Balance"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	red := &DifferentialFormat{FontColor: "9C0006", FillColor: "FFC7CE"}
+	err = ws.AddColumnConditionalFormatting("balance", &ConditionalFormatRule{
+		Type:     ConditionalFormatCellIs,
+		Operator: "lessThan",
+		Formulas: []string{"0"},
+		Format:   red,
+	}, &ConditionalFormatRule{
+		Type:   ConditionalFormatDataBar,
+		Colors: []string{"638EC6"},
+	})
+	if err != nil {
+		t.Errorf("failed to add conditional formatting: %v", err)
+		return
+	}
+	err = ws.AddConditionalFormatting("A2:A10", &ConditionalFormatRule{
+		Type:   ConditionalFormatDuplicateValues,
+		Format: red,
+	})
+	if err != nil {
+		t.Errorf("failed to add conditional formatting: %v", err)
+		return
+	}
+
+	for i := 0; i < 3; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("account")
+		cell.Value = "checking"
+		cell, _ = row.AddCellWithKey("balance")
+		cell.Value = i - 1
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	expected := `<conditionalFormatting sqref="B2:B4"><cfRule type="cellIs" dxfId="0" priority="1" operator="lessThan"><formula>0</formula></cfRule><cfRule type="dataBar" priority="2"><dataBar><cfvo type="min"/><cfvo type="max"/><color rgb="FF638EC6"/></dataBar></cfRule></conditionalFormatting><conditionalFormatting sqref="A2:A10"><cfRule type="duplicateValues" dxfId="0" priority="3"/></conditionalFormatting>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected conditional formatting, found: %s", content)
+	}
+
+	if len(wb.styles.dxfs) != 1 || wb.styles.dxfs[0].FillColor != "FFFFC7CE" {
+		t.Errorf("unexpected differential formats, found: %v", wb.styles.dxfs)
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
+
+func Test_Worksheet_AddConditionalFormatting_ShouldFail_WhenGivenInvalidRules(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Balances",
+	})
+
+	rules := []*ConditionalFormatRule{
+		&ConditionalFormatRule{Type: ConditionalFormatCellIs, Operator: "between", Formulas: []string{"0"}, Format: &DifferentialFormat{}},
+		&ConditionalFormatRule{Type: ConditionalFormatExpression, Formulas: []string{"$A2>0"}},
+		&ConditionalFormatRule{Type: ConditionalFormatColorScale, Colors: []string{"FF0000"}},
+		&ConditionalFormatRule{Type: ConditionalFormatIconSet, IconSet: "Arrows"},
+		&ConditionalFormatRule{Type: ConditionalFormatIconSet, IconSet: "3Nonsense"},
+		&ConditionalFormatRule{Type: ConditionalFormatIconSet, IconSet: "4"},
+		&ConditionalFormatRule{Type: ConditionalFormatTop10, Format: &DifferentialFormat{}},
+		&ConditionalFormatRule{Type: "unknown"},
+	}
+	for _, rule := range rules {
+		err := ws.AddConditionalFormatting("A1:A10", rule)
+		if err == nil {
+			t.Errorf("expected an error when adding the rule %v", rule)
+		}
+	}
+}
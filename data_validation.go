@@ -0,0 +1,261 @@
+package xlsx
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DataValidationType is the type of a data validation.
+type DataValidationType string
+
+// Data validation types.
+const (
+	// DataValidationList only accepts one of Values, or a value from the Source range.
+	DataValidationList DataValidationType = "list"
+	// DataValidationWhole only accepts whole numbers within Min and Max.
+	DataValidationWhole DataValidationType = "whole"
+	// DataValidationDecimal only accepts numbers within Min and Max.
+	DataValidationDecimal DataValidationType = "decimal"
+	// DataValidationDate only accepts dates within Min and Max.
+	DataValidationDate DataValidationType = "date"
+	// DataValidationTextLength only accepts text whose length is within Min and Max.
+	DataValidationTextLength DataValidationType = "textLength"
+	// DataValidationCustom only accepts values for which Formula evaluates to true.
+	DataValidationCustom DataValidationType = "custom"
+)
+
+var dataValidationOperators = map[string]bool{
+	"between":            true,
+	"notBetween":         true,
+	"equal":              true,
+	"notEqual":           true,
+	"greaterThan":        true,
+	"greaterThanOrEqual": true,
+	"lessThan":           true,
+	"lessThanOrEqual":    true,
+}
+
+// DataValidation restricts the values users can type into cells. Only the fields used by its Type are considered.
+type DataValidation struct {
+	Type DataValidationType
+	// Values are the literal entries of a list validation. They can't contain commas.
+	Values []string
+	// Source is a range holding the entries of a list validation, such as Lookup!$A$1:$A$10. Takes precedence over
+	// Values.
+	Source string
+	// Operator compares the value with Min and Max in whole, decimal, date and textLength validations, one of between,
+	// the default, notBetween, equal, notEqual, greaterThan, greaterThanOrEqual, lessThan and lessThanOrEqual. Operators
+	// taking a single operand use Min, except lessThan and lessThanOrEqual which use Max.
+	Operator string
+	// Min and Max are numbers, time.Time values or formulas.
+	Min interface{}
+	Max interface{}
+	// Formula is the formula of a custom validation, relative to the top left cell of the range.
+	Formula string
+	// AllowBlank accepts empty cells.
+	AllowBlank bool
+	// InputTitle and InputMessage are shown when the cell is selected.
+	InputTitle   string
+	InputMessage string
+	// ErrorStyle is one of stop, the default, warning and information.
+	ErrorStyle   string
+	ErrorTitle   string
+	ErrorMessage string
+}
+
+type dataValidation struct {
+	sqref     string
+	columnKey string
+	attrs     string
+	formula1  string
+	formula2  string
+}
+
+// AddDataValidation restricts the values of the cells in a range such as C2:C100, several ranges may be given
+// separated by spaces.
+func (ws *Worksheet) AddDataValidation(ref string, v *DataValidation) error {
+	if ws.committed {
+		return errors.Wrap(ErrCommitted, "can't add data validations to the worksheet")
+	}
+
+	if ref == "" {
+		return errors.New("can't add a data validation without a range")
+	}
+	for _, r := range strings.Fields(ref) {
+		var err error
+		if strings.Contains(r, ":") {
+			_, _, _, _, err = parseRange(r)
+		} else {
+			_, _, err = parseIdentifier(r)
+		}
+		if err != nil {
+			return errors.Wrap(err, "can't add a data validation")
+		}
+	}
+
+	dv, err := createDataValidation(v)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s validation", v.Type)
+	}
+	dv.sqref = strings.ToUpper(ref)
+
+	ws.dataValidations = append(ws.dataValidations, dv)
+
+	return nil
+}
+
+func createDataValidation(v *DataValidation) (*dataValidation, error) {
+	dv := &dataValidation{}
+	attrs := fmt.Sprintf(` type="%s"`, v.Type)
+
+	switch v.Type {
+	case DataValidationList:
+		if v.Source != "" {
+			dv.formula1 = strings.TrimPrefix(v.Source, "=")
+			break
+		}
+		if len(v.Values) == 0 {
+			return nil, errors.New("a list needs either values or a source")
+		}
+		for i := 0; i < len(v.Values); i++ {
+			if strings.Contains(v.Values[i], ",") {
+				return nil, errors.Errorf("the list value %q can't contain commas", v.Values[i])
+			}
+		}
+		list := strings.Join(v.Values, ",")
+		if len([]rune(list)) > 255 {
+			return nil, errors.New("the list values can't be longer than 255 characters, use a source range instead")
+		}
+		dv.formula1 = `"` + strings.Replace(list, `"`, `""`, -1) + `"`
+	case DataValidationWhole, DataValidationDecimal, DataValidationDate, DataValidationTextLength:
+		operator := v.Operator
+		if operator == "" {
+			operator = "between"
+		}
+		if !dataValidationOperators[operator] {
+			return nil, errors.Errorf("unknown operator %q", operator)
+		}
+		attrs += fmt.Sprintf(` operator="%s"`, operator)
+
+		first, second := v.Min, v.Max
+		if operator == "lessThan" || operator == "lessThanOrEqual" {
+			first, second = v.Max, nil
+		} else if operator != "between" && operator != "notBetween" {
+			second = nil
+		}
+		if first == nil || (second == nil && (operator == "between" || operator == "notBetween")) {
+			return nil, errors.Errorf("missing operands for the %s operator", operator)
+		}
+
+		var err error
+		dv.formula1, err = dataValidationOperand(first)
+		if err != nil {
+			return nil, err
+		}
+		if second != nil {
+			dv.formula2, err = dataValidationOperand(second)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case DataValidationCustom:
+		if v.Formula == "" {
+			return nil, errors.New("a custom validation needs a formula")
+		}
+		dv.formula1 = strings.TrimPrefix(v.Formula, "=")
+	default:
+		return nil, errors.Errorf("unknown data validation type %q", v.Type)
+	}
+
+	switch v.ErrorStyle {
+	case "", "stop":
+	case "warning", "information":
+		attrs += fmt.Sprintf(` errorStyle="%s"`, v.ErrorStyle)
+	default:
+		return nil, errors.Errorf("unknown error style %q", v.ErrorStyle)
+	}
+
+	if v.AllowBlank {
+		attrs += ` allowBlank="1"`
+	}
+	if v.InputTitle != "" || v.InputMessage != "" {
+		attrs += ` showInputMessage="1"`
+	}
+	attrs += ` showErrorMessage="1"`
+	if v.InputTitle != "" {
+		attrs += fmt.Sprintf(` promptTitle="%s"`, escapeXML(v.InputTitle))
+	}
+	if v.InputMessage != "" {
+		attrs += fmt.Sprintf(` prompt="%s"`, escapeXML(v.InputMessage))
+	}
+	if v.ErrorTitle != "" {
+		attrs += fmt.Sprintf(` errorTitle="%s"`, escapeXML(v.ErrorTitle))
+	}
+	if v.ErrorMessage != "" {
+		attrs += fmt.Sprintf(` error="%s"`, escapeXML(v.ErrorMessage))
+	}
+	dv.attrs = attrs
+
+	return dv, nil
+}
+
+// dataValidationOperand turns numbers and dates into their formula representation, strings are taken as formulas.
+func dataValidationOperand(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return strings.TrimPrefix(value, "="), nil
+	case time.Time:
+		return strconv.FormatFloat(timeToExcelTime(timeToUTCTime(value)), 'f', -1, 64), nil
+	}
+
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v), nil
+	}
+
+	return "", errors.Wrapf(ErrUnsupportedType, "%T is not supported as a data validation operand", v)
+}
+
+func (ws *Worksheet) writeDataValidations(f *os.File) error {
+	var validations []*dataValidation
+	for i := 0; i < len(ws.dataValidations); i++ {
+		dv := ws.dataValidations[i]
+		if dv.columnKey != "" {
+			sqref := ws.columnDataRange(dv.columnKey)
+			if sqref == "" {
+				continue
+			}
+			dv.sqref = sqref
+		}
+		validations = append(validations, dv)
+	}
+	if len(validations) == 0 {
+		return nil
+	}
+
+	_, err := f.WriteString(startDataValidationsFormat(len(validations)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_DATA_VALIDATIONS to file %s", ws.filePath)
+	}
+	for i := 0; i < len(validations); i++ {
+		dv := validations[i]
+		_, err = f.WriteString(dataValidationFormat(dv.sqref, dv.attrs, dv.formula1, dv.formula2))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append a data validation to file %s", ws.filePath)
+		}
+	}
+	_, err = f.WriteString(endDataValidations)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_DATA_VALIDATIONS to file %s", ws.filePath)
+	}
+
+	return nil
+}
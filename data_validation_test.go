@@ -0,0 +1,97 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Worksheet_DefineColumns_ShouldWriteColumnValidations_WhenColumnsHaveValidations(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-6.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Tasks",
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "task", Value: "Task"},
+		&WorksheetColumn{Key: "status", Value: "Status", Validation: &DataValidation{
+			Type:         DataValidationList,
+			Values:       []string{"Open", "Closed"},
+			AllowBlank:   true,
+			ErrorMessage: "Pick a status from the list",
+		}},
+		&WorksheetColumn{Key: "due", Value: "Due", Validation: &DataValidation{
+			Type: DataValidationDate,
+			Min:  time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+			Max:  "TODAY()",
+		}},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+	err = ws.AddDataValidation("E2:E10", &DataValidation{
+		Type:     DataValidationWhole,
+		Operator: "greaterThan",
+		Min:      0,
+	})
+	if err != nil {
+		t.Errorf("failed to add a data validation: %v", err)
+		return
+	}
+
+	for i := 0; i < 3; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("task")
+		cell.Value = "review"
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	expected := `<dataValidations count="3"><dataValidation type="list" allowBlank="1" showErrorMessage="1" error="Pick a status from the list" sqref="B2:B4"><formula1>&quot;Open,Closed&quot;</formula1></dataValidation><dataValidation type="date" operator="between" showErrorMessage="1" sqref="C2:C4"><formula1>43466</formula1><formula2>TODAY()</formula2></dataValidation><dataValidation type="whole" operator="greaterThan" showErrorMessage="1" sqref="E2:E10"><formula1>0</formula1></dataValidation></dataValidations>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected data validations, found: %s", content)
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
+
+func Test_Worksheet_AddDataValidation_ShouldFail_WhenGivenInvalidValidations(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Tasks",
+	})
+
+	validations := []*DataValidation{
+		&DataValidation{Type: DataValidationList},
+		&DataValidation{Type: DataValidationList, Values: []string{"a,b"}},
+		&DataValidation{Type: DataValidationDecimal, Min: 1},
+		&DataValidation{Type: DataValidationTextLength, Operator: "longerThan", Min: 1},
+		&DataValidation{Type: DataValidationCustom},
+		&DataValidation{Type: DataValidationWhole, Operator: "equal", Min: []int{1}},
+		&DataValidation{Type: "unknown"},
+	}
+	for _, v := range validations {
+		err := ws.AddDataValidation("A1:A10", v)
+		if err == nil {
+			t.Errorf("expected an error when adding the validation %v", v)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package xlsx
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+type definedName struct {
+	name     string
+	refersTo string
+	// scope is the worksheet the name is local to, nil for workbook scoped names.
+	scope        *Worksheet
+	localSheetID int
+	hidden       bool
+}
+
+// DefineName defines a name, such as TaxRate, that formulas can use instead of the reference or formula it refers to,
+// such as Settings!$B$2. The name is scoped to the given worksheet or, when it's nil, to the whole workbook.
+func (wb *Workbook) DefineName(name, refersTo string, scope *Worksheet) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if wb.committed {
+		return errors.Wrap(ErrCommitted, "can't define names on the workbook")
+	}
+
+	if scope != nil && scope.workbook != wb {
+		return errors.New("can't scope a name to a worksheet from another workbook")
+	}
+
+	err := validateName(name)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(strings.ToLower(name), "_xlnm.") {
+		return errors.Wrapf(ErrInvalidName, "name %q is reserved for built-in names", name)
+	}
+
+	refersTo = strings.TrimPrefix(refersTo, "=")
+	if refersTo == "" {
+		return errors.Errorf("name %s must refer to something", name)
+	}
+
+	for i := 0; i < len(wb.names); i++ {
+		if wb.names[i].scope == scope && strings.EqualFold(wb.names[i].name, name) {
+			return errors.Wrapf(ErrInvalidName, "name %s is already defined", name)
+		}
+	}
+
+	wb.names = append(wb.names, &definedName{
+		name:     name,
+		refersTo: refersTo,
+		scope:    scope,
+	})
+
+	return nil
+}
+
+// columnNames are the names NameColumns defines for the data rows of each column.
+func (ws *Worksheet) columnNames() []*definedName {
+	if !ws.options.NameColumns {
+		return nil
+	}
+
+	y1, y2 := ws.headerRows, ws.rowsCount-ws.footerRows-1
+	if y2 < y1 {
+		y2 = y1
+	}
+
+	names := make([]*definedName, 0, len(ws.columns))
+	for i := 0; i < len(ws.columns); i++ {
+		names = append(names, &definedName{
+			name:         sanitizeName(ws.name + "_" + ws.columns[i].Key),
+			refersTo:     createAbsoluteRangeFromCoords(ws.name, i, y1, i, y2),
+			localSheetID: -1,
+		})
+	}
+	return names
+}
+
+// sanitizeName replaces the characters that can't be used in a defined name by underscores, prefixes the names that
+// would look like a cell reference with one and cuts the name to the length Excel allows.
+func sanitizeName(name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if sanitized == "" || !unicode.IsLetter([]rune(sanitized)[0]) || cellReferencePattern.MatchString(sanitized) {
+		sanitized = "_" + sanitized
+	}
+	if runes := []rune(sanitized); len(runes) > maxNameLength {
+		sanitized = string(runes[:maxNameLength])
+	}
+	return sanitized
+}
+
+// definedNames lists the names written to workbook.xml, including the built-in ones required by the worksheets.
+func (wb *Workbook) definedNames() []*definedName {
+	var names []*definedName
+	for i := 0; i < len(wb.worksheets); i++ {
+		ws := wb.worksheets[i]
+		if ws.autoFilter != nil {
+			names = append(names, &definedName{
+				name:         "_xlnm._FilterDatabase",
+				refersTo:     ws.autoFilter.absolute,
+				localSheetID: i,
+				hidden:       true,
+			})
+		}
+		names = append(names, ws.printNames(i)...)
+		names = append(names, ws.columnNames()...)
+	}
+
+	for i := 0; i < len(wb.names); i++ {
+		n := *wb.names[i]
+		n.localSheetID = -1
+		for j := 0; j < len(wb.worksheets); j++ {
+			if wb.worksheets[j] == n.scope {
+				n.localSheetID = j
+			}
+		}
+		names = append(names, &n)
+	}
+
+	return names
+}
+
+// validateDefinedNames checks that names are valid and don't collide with each other or with table names. Table names
+// are workbook wide, so they collide with the names of every scope.
+func (wb *Workbook) validateDefinedNames() error {
+	tables := make(map[string]bool)
+	for i := 0; i < len(wb.worksheets); i++ {
+		if t := wb.worksheets[i].table; t != nil {
+			tables[strings.ToLower(t.name)] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	names := wb.definedNames()
+	for i := 0; i < len(names); i++ {
+		n := names[i]
+		if !strings.HasPrefix(n.name, "_xlnm.") {
+			err := validateName(n.name)
+			if err != nil {
+				return err
+			}
+		}
+		key := fmt.Sprintf("%d:%s", n.localSheetID, strings.ToLower(n.name))
+		if seen[key] || tables[strings.ToLower(n.name)] {
+			return errors.Wrapf(ErrInvalidName, "name %s is defined more than once, or collides with a table name", n.name)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
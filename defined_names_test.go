@@ -0,0 +1,134 @@
+package xlsx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Workbook_DefineName_ShouldWriteNames_WhenGivenValidNames(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-11.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:        "Orders",
+		NameColumns: true,
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+		&WorksheetColumn{Key: "customer", Value: "Customer"},
+		&WorksheetColumn{Key: "amount", Value: "Amount"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	err = wb.DefineName("TaxRate", "=0.2", nil)
+	if err != nil {
+		t.Errorf("failed to define a name: %v", err)
+		return
+	}
+	err = wb.DefineName("FirstOrder", "Orders!$A$2", ws)
+	if err != nil {
+		t.Errorf("failed to define a name: %v", err)
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("amount")
+		cell.Value = i
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	content, err := readPart(wb, "xl/workbook.xml")
+	if err != nil {
+		t.Errorf("failed to read the workbook file: %v", err)
+		return
+	}
+	for _, expected := range []string{
+		`<definedName name="Orders_amount">&apos;Orders&apos;!$C$2:$C$6</definedName>`,
+		`<definedName name="TaxRate">0.2</definedName>`,
+		`<definedName name="FirstOrder" localSheetId="0">Orders!$A$2</definedName>`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("workbook is missing %s, found: %s", expected, content)
+		}
+	}
+}
+
+func Test_Workbook_DefineName_ShouldFail_WhenGivenInvalidNames(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Orders",
+	})
+
+	err := wb.DefineName("Total", "Orders!$A$1", nil)
+	if err != nil {
+		t.Errorf("failed to define a name: %v", err)
+		return
+	}
+	err = wb.DefineName("total", "Orders!$A$1", ws)
+	if err != nil {
+		t.Errorf("failed to define a name with the same name in another scope: %v", err)
+		return
+	}
+
+	for _, name := range []string{"TOTAL", "", "1st", "A1", "R1C1", "has space", "_xlnm.Print_Area"} {
+		err = wb.DefineName(name, "Orders!$A$1", nil)
+		if err == nil {
+			t.Errorf("expected an error when defining the name %q", name)
+		}
+	}
+}
+
+func Test_Workbook_Commit_ShouldFail_WhenScopedNameCollidesWithTableName(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:    "Orders",
+		AsTable: &TableOptions{Name: "Sales"},
+	})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+
+	err := wb.DefineName("sales", "Orders!$A$1", ws)
+	if err != nil {
+		t.Errorf("failed to define a name: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if !errors.Is(err, ErrInvalidName) {
+		t.Errorf("expected ErrInvalidName, found: %v", err)
+	}
+}
+
+func Test_sanitizeName_ShouldReturnValidNames(t *testing.T) {
+	for _, name := range []string{"A1", "R1C1", "c", "1st", "has space", strings.Repeat("a", 300)} {
+		sanitized := sanitizeName(name)
+		err := validateName(sanitized)
+		if err != nil {
+			t.Errorf("expected %q to be sanitized to a valid name, found %q: %v", name, sanitized, err)
+		}
+	}
+}
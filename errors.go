@@ -0,0 +1,97 @@
+package xlsx
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Errors returned by the package, usually wrapped with more context. Use errors.Is to check for them.
+var (
+	// ErrCommitted is returned when changing a workbook, worksheet or row that was already committed.
+	ErrCommitted = errors.New("already committed")
+	// ErrNoColumns is returned when something needs the columns defined with DefineColumns and there are none.
+	ErrNoColumns = errors.New("no columns were defined")
+	// ErrColumnsDefined is returned when adding cells without keys to a worksheet with columns.
+	ErrColumnsDefined = errors.New("columns were defined")
+	// ErrUnknownColumn is returned when a key doesn't match any of the columns defined with DefineColumns.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrUnsupportedType is returned when a value can't be written to the file.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrNoRows is returned when committing rows and there are none pending.
+	ErrNoRows = errors.New("there are no rows to commit")
+	// ErrOutOfOrder is returned when adding a row or a cell before the last one, as they are written sequentially.
+	ErrOutOfOrder = errors.New("rows and cells must be added in order")
+	// ErrPendingRows is returned when committing a worksheet that still has rows to be committed.
+	ErrPendingRows = errors.New("there are pending rows")
+	// ErrNoWorksheets is returned when committing a workbook without worksheets.
+	ErrNoWorksheets = errors.New("a workbook needs at least one worksheet")
+	// ErrPendingWorksheets is returned when committing a workbook that still has worksheets to be committed.
+	ErrPendingWorksheets = errors.New("there are pending worksheets")
+	// ErrAborted is returned when using a workbook whose commit was aborted as its context was done.
+	ErrAborted = errors.New("the workbook was aborted")
+	// ErrInvalidName is returned when a worksheet, defined name or table name is not accepted by Excel.
+	ErrInvalidName = errors.New("invalid name")
+	// ErrFileExists is returned when committing a workbook that must not overwrite its file and the file exists.
+	ErrFileExists = errors.New("the file already exists")
+)
+
+// Limit is one of the grid limits Excel enforces on a worksheet.
+type Limit string
+
+// Grid limits.
+const (
+	RowsLimit       Limit = "rows"
+	ColumnsLimit    Limit = "columns"
+	CellLengthLimit Limit = "characters in a cell"
+)
+
+// LimitError is returned when a worksheet would go past one of Excel's grid limits.
+type LimitError struct {
+	Sheet string
+	Limit Limit
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("worksheet %q exceeds the limit of %d %s", e.Sheet, e.Max, e.Limit)
+}
+
+// CellError is returned when a cell can't be added or written, Err is the reason.
+type CellError struct {
+	Sheet string
+	// Row is the row number as shown by Excel, starting at 1.
+	Row int
+	// Column is the column letters, it's empty if the key doesn't match any column.
+	Column string
+	// Key is the key of the column, it's empty if no columns were defined.
+	Key string
+	Err error
+}
+
+func (e *CellError) Error() string {
+	cell := fmt.Sprintf("row %d", e.Row)
+	if e.Column != "" {
+		cell = fmt.Sprintf("cell %s%d", e.Column, e.Row)
+	}
+	if e.Key != "" {
+		cell += fmt.Sprintf(" (%s)", e.Key)
+	}
+	return fmt.Sprintf("%s of worksheet %q: %v", cell, e.Sheet, e.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// newCellError creates a CellError for the cell.
+func newCellError(cell *Cell, err error) *CellError {
+	return &CellError{
+		Sheet:  cell.row.worksheet.name,
+		Row:    cell.row.index + 1,
+		Column: numericToLetters(cell.index),
+		Key:    cell.Key,
+		Err:    err,
+	}
+}
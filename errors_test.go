@@ -0,0 +1,112 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Worksheet_AddRow_ShouldReturnErrCommitted_WhenWorksheetIsCommitted(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = "value"
+	ws.CommitRows()
+	err := ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	_, err = ws.AddRow()
+	if !errors.Is(err, ErrCommitted) {
+		t.Errorf("expected ErrCommitted, found: %v", err)
+	}
+}
+
+func Test_Worksheet_CommitRows_ShouldReturnCellError_WhenValueIsNotSupported(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+		&WorksheetColumn{Key: "tags", Value: "Tags"},
+	})
+
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCellWithKey("tags")
+	cell.Value = []string{"a", "b"}
+
+	err := ws.CommitRows()
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType, found: %v", err)
+	}
+
+	var cellErr *CellError
+	if !errors.As(err, &cellErr) {
+		t.Errorf("expected a CellError, found: %v", err)
+		return
+	}
+	if cellErr.Sheet != "Data" || cellErr.Row != 2 || cellErr.Column != "B" || cellErr.Key != "tags" {
+		t.Errorf("unexpected cell error: %+v", cellErr)
+	}
+}
+
+func Test_Row_AddCellWithKey_ShouldReturnErrUnknownColumn_WhenKeyIsNotDefined(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+
+	row, _ := ws.AddRow()
+	_, err := row.AddCellWithKey("amount")
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, found: %v", err)
+	}
+	if err.Error() != `row 2 (amount) of worksheet "Data": unknown column` {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
+func Test_Worksheet_CommitRows_ShouldNotWritePartialRow_WhenCellIsTooLong(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = "a"
+	cell, _ = row.AddCell()
+	cell.Value = strings.Repeat("b", maxCellLength+1)
+
+	err := ws.CommitRows()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Errorf("expected a LimitError, found: %v", err)
+	}
+
+	row, _ = ws.AddRow()
+	cell, _ = row.AddCell()
+	cell.Value = "c"
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<sheetData><row r="2"><c r="A2" t="str"><v>c</v></c></row></sheetData>`) {
+		t.Errorf("expected only the valid row to be written, found: %s", content)
+	}
+}
@@ -0,0 +1,171 @@
+package xlsx
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBatchRows  = 1000
+	defaultBatchBytes = 4 << 20
+	// cellOverhead is roughly the size of the markup around the value of a cell.
+	cellOverhead = 32
+)
+
+// BatchOptions controls how often the rows fed to a worksheet are committed. Rows are committed once either limit is
+// reached, whichever comes first.
+type BatchOptions struct {
+	// Rows is the number of rows kept in memory, 1000 by default.
+	Rows int
+	// Bytes is roughly the size of the rows kept in memory, 4 MiB by default.
+	Bytes int
+}
+
+// feeder adds rows given as values and commits them in batches.
+type feeder struct {
+	ctx   context.Context
+	ws    *Worksheet
+	opts  BatchOptions
+	rows  int
+	bytes int
+	// keys are the keys of the columns the values are set to, once the columns are defined.
+	keys []string
+}
+
+func newFeeder(ctx context.Context, ws *Worksheet, opts *BatchOptions) *feeder {
+	f := &feeder{ctx: ctx, ws: ws}
+	if opts != nil {
+		f.opts = *opts
+	}
+	if f.opts.Rows <= 0 {
+		f.opts.Rows = defaultBatchRows
+	}
+	if f.opts.Bytes <= 0 {
+		f.opts.Bytes = defaultBatchBytes
+	}
+	return f
+}
+
+// add adds a row with the values as cells, in the order of the columns if they were defined. Nil values leave their
+// cells empty.
+func (f *feeder) add(values []interface{}) error {
+	if f.keys == nil && f.ws.columns != nil {
+		for i := 0; i < len(f.ws.columns); i++ {
+			f.keys = append(f.keys, f.ws.columns[i].Key)
+		}
+	}
+
+	var err error
+	if f.keys != nil {
+		if len(values) > len(f.keys) {
+			return errors.Wrapf(ErrUnknownColumn, "can't add a row with %d values for %d columns", len(values), len(f.keys))
+		}
+		err = f.ws.addValues(f.keys[:len(values)], values)
+	} else {
+		err = f.addCells(values)
+	}
+	if err != nil {
+		return err
+	}
+	return f.added(valuesSize(values))
+}
+
+// valuesSize is roughly how much memory the cells of the values take.
+func valuesSize(values []interface{}) int {
+	size := 0
+	for i := 0; i < len(values); i++ {
+		if values[i] == nil {
+			continue
+		}
+		size += cellOverhead
+		switch v := values[i].(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		}
+	}
+	return size
+}
+
+// addCells adds a row with the values as cells, for worksheets without columns.
+func (f *feeder) addCells(values []interface{}) error {
+	row, err := f.ws.AddRow()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(values); i++ {
+		if values[i] == nil {
+			continue
+		}
+		cell, err := row.SetCell(i)
+		if err != nil {
+			row.discard()
+			return err
+		}
+		cell.Value = values[i]
+	}
+	return nil
+}
+
+// added counts a row added to the worksheet, size being roughly how much memory it takes, and commits the rows once
+// either limit of the batch is reached.
+func (f *feeder) added(size int) error {
+	f.rows++
+	f.bytes += size
+	if f.rows >= f.opts.Rows || f.bytes >= f.opts.Bytes {
+		return f.flush()
+	}
+	return nil
+}
+
+// drain discards the rows until the channel is closed.
+func drain(rows <-chan []interface{}) {
+	for range rows {
+	}
+}
+
+// flush commits the rows kept in memory, if any.
+func (f *feeder) flush() error {
+	f.rows, f.bytes = 0, 0
+	if !f.ws.hasPendingRows() {
+		return nil
+	}
+	return f.ws.CommitRowsContext(f.ctx)
+}
+
+// WriteFrom adds a row for every slice of values received, until the channel is closed, and commits them in batches.
+// The values are set in the order of the columns if they were defined. It stops on the first error, or when the
+// context is done, in which case the workbook is aborted as by CommitRowsContext, whether it was waiting for rows or
+// committing them. Once it stops early, the rows still sent are discarded until the channel is closed, so producers
+// aren't left blocked, but they should still stop sending once the context is done.
+func (ws *Worksheet) WriteFrom(ctx context.Context, rows <-chan []interface{}) error {
+	return ws.WriteFromWithOptions(ctx, rows, &BatchOptions{})
+}
+
+// WriteFromWithOptions is just like WriteFrom but allows the size of the batches to be set.
+func (ws *Worksheet) WriteFromWithOptions(ctx context.Context, rows <-chan []interface{}, opts *BatchOptions) (err error) {
+	defer func() {
+		if err != nil {
+			go drain(rows)
+		}
+	}()
+
+	f := newFeeder(ctx, ws, opts)
+	for {
+		select {
+		case <-ctx.Done():
+			ws.workbook.abort()
+			return ctx.Err()
+		case values, ok := <-rows:
+			if !ok {
+				return f.flush()
+			}
+			err := f.add(values)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
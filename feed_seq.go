@@ -0,0 +1,31 @@
+//go:build go1.23
+
+package xlsx
+
+import (
+	"context"
+	"iter"
+)
+
+// WriteSeq is just like WriteFrom but takes the rows from an iterator, which is stopped on the first error or when the
+// context is done.
+func (ws *Worksheet) WriteSeq(ctx context.Context, rows iter.Seq[[]interface{}]) error {
+	return ws.WriteSeqWithOptions(ctx, rows, &BatchOptions{})
+}
+
+// WriteSeqWithOptions is just like WriteSeq but allows the size of the batches to be set.
+func (ws *Worksheet) WriteSeqWithOptions(ctx context.Context, rows iter.Seq[[]interface{}], opts *BatchOptions) error {
+	f := newFeeder(ctx, ws, opts)
+	for values := range rows {
+		err := ctx.Err()
+		if err != nil {
+			ws.workbook.abort()
+			return err
+		}
+		err = f.add(values)
+		if err != nil {
+			return err
+		}
+	}
+	return f.flush()
+}
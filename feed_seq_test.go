@@ -0,0 +1,77 @@
+//go:build go1.23
+
+package xlsx
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Worksheet_WriteSeq_ShouldWriteRowsFromIterator(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	rows := func(yield func([]interface{}) bool) {
+		for i := 0; i < 10; i++ {
+			if !yield([]interface{}{i, nil, "x"}) {
+				return
+			}
+		}
+	}
+	err := ws.WriteSeqWithOptions(context.Background(), rows, &BatchOptions{Rows: 3})
+	if err != nil {
+		t.Errorf("failed to write the rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if count := strings.Count(string(content), "<row "); count != 10 {
+		t.Errorf("expected 10 rows, found %d", count)
+	}
+	if !strings.Contains(string(content), `<row r="1"><c r="A1" t="str"><v>0</v></c><c r="C1" t="str"><v>x</v></c></row>`) {
+		t.Errorf("unexpected sheet data, found: %s", content)
+	}
+}
+
+func Test_Worksheet_WriteSeq_ShouldStop_WhenContextIsCanceled(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	yielded := 0
+	rows := func(yield func([]interface{}) bool) {
+		for i := 0; i < 10; i++ {
+			yielded++
+			if i == 4 {
+				cancel()
+			}
+			if !yield([]interface{}{i}) {
+				return
+			}
+		}
+	}
+	err := ws.WriteSeq(ctx, rows)
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, found: %v", err)
+	}
+	if yielded != 5 {
+		t.Errorf("expected the iterator to stop after 5 rows, found %d", yielded)
+	}
+	_, err = wb.AddWorksheet(&WorksheetOptions{})
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected the workbook to be aborted, found: %v", err)
+	}
+}
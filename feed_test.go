@@ -0,0 +1,195 @@
+package xlsx
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Worksheet_WriteFrom_ShouldWriteRowsFromProducers(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "producer", Value: "Producer"},
+		&WorksheetColumn{Key: "n", Value: "N"},
+		&WorksheetColumn{Key: "note", Value: "Note"},
+	})
+
+	rows := make(chan []interface{})
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for n := 0; n < 25; n++ {
+				rows <- []interface{}{p, n}
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		close(rows)
+	}()
+
+	err := ws.WriteFromWithOptions(context.Background(), rows, &BatchOptions{Rows: 7})
+	if err != nil {
+		t.Errorf("failed to write the rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if count := strings.Count(string(content), "<row "); count != 101 {
+		t.Errorf("expected 101 rows, found %d", count)
+	}
+	if strings.Contains(string(content), `r="C2"`) {
+		t.Errorf("expected the missing values to be left empty, found: %s", content)
+	}
+}
+
+func Test_Worksheet_WriteFrom_ShouldAbort_WhenContextIsCanceled(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := make(chan []interface{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(rows)
+		rows <- []interface{}{1}
+		cancel()
+		// Still sending once the context is done must not block forever.
+		for i := 0; i < 3; i++ {
+			rows <- []interface{}{i}
+		}
+	}()
+
+	err := ws.WriteFrom(ctx, rows)
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, found: %v", err)
+	}
+	_, err = wb.AddWorksheet(&WorksheetOptions{})
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected the workbook to be aborted, found: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the producer to be released")
+	}
+}
+
+func Test_feeder_flush_ShouldAbort_WhenContextIsCanceled(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFeeder(ctx, ws, &BatchOptions{Rows: 2})
+	err := f.add([]interface{}{1})
+	if err != nil {
+		t.Errorf("failed to add the row: %v", err)
+		return
+	}
+	cancel()
+	err = f.add([]interface{}{2})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, found: %v", err)
+	}
+	_, err = wb.AddWorksheet(&WorksheetOptions{})
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected the workbook to be aborted, found: %v", err)
+	}
+}
+
+func Test_Worksheet_WriteFrom_ShouldReportFirstError(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+
+	rows := make(chan []interface{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(rows)
+		rows <- []interface{}{1, "extra"}
+		rows <- []interface{}{[]int{2}}
+		rows <- []interface{}{3}
+	}()
+
+	err := ws.WriteFrom(context.Background(), rows)
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, found: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the producer to be released")
+	}
+}
+
+func Test_feeder_add_ShouldCommit_WhenBatchBytesAreReached(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	f := newFeeder(context.Background(), ws, &BatchOptions{Bytes: 100})
+	err := f.add([]interface{}{"short"})
+	if err != nil {
+		t.Errorf("failed to add the row: %v", err)
+		return
+	}
+	if len(ws.pendingRows) != 1 {
+		t.Errorf("expected 1 pending row, found %d", len(ws.pendingRows))
+	}
+
+	err = f.add([]interface{}{strings.Repeat("a", 100)})
+	if err != nil {
+		t.Errorf("failed to add the row: %v", err)
+		return
+	}
+	if len(ws.pendingRows) != 0 {
+		t.Errorf("expected the rows to be committed, found %d pending rows", len(ws.pendingRows))
+	}
+}
+
+func Test_feeder_add_ShouldNotLeaveARow_WhenValueIsNotSupported(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+
+	f := newFeeder(context.Background(), ws, nil)
+	err := f.add([]interface{}{[]int{1}})
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType, found: %v", err)
+	}
+	err = f.add([]interface{}{1, "extra"})
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, found: %v", err)
+	}
+	err = f.add([]interface{}{2})
+	if err != nil {
+		t.Errorf("failed to add the row: %v", err)
+		return
+	}
+	if len(ws.pendingRows) != 1 || ws.pendingRows[0].index != 1 {
+		t.Errorf("expected the valid row right after the header, found %d pending rows", len(ws.pendingRows))
+	}
+}
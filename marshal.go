@@ -0,0 +1,347 @@
+package xlsx
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// structField is a field of a struct, possibly promoted from an embedded struct, written as a cell.
+type structField struct {
+	index     []int
+	key       string
+	header    string
+	format    string
+	width     float64
+	omitEmpty bool
+	// named indicates whether or not the key is set by the tag.
+	named bool
+}
+
+// structFields caches the fields of every struct type written so far.
+var structFields sync.Map
+
+// typeFields returns the fields of a struct type in the order they are declared, embedded structs are flattened as Go
+// promotes their fields. Fields are tagged as in `xlsx:"key,header=Order ID,format=0.00,width=12,omitempty"`, they are
+// skipped with `xlsx:"-"` and the field name is used as key and header when the tag is missing.
+func typeFields(t reflect.Type) ([]*structField, error) {
+	cached, ok := structFields.Load(t)
+	if ok {
+		return cached.([]*structField), nil
+	}
+
+	fields, err := appendTypeFields(nil, t, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err = dominantFields(fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid fields in %s", t)
+	}
+
+	structFields.Store(t, fields)
+
+	return fields, nil
+}
+
+// dominantFields keeps, of the fields sharing a key, the one Go promotes as encoding/json does: the shallowest one, or
+// the one named by its tag among those as shallow. The other ties are ambiguous.
+func dominantFields(fields []*structField) ([]*structField, error) {
+	byKey := make(map[string][]*structField, len(fields))
+	for i := 0; i < len(fields); i++ {
+		byKey[fields[i].key] = append(byKey[fields[i].key], fields[i])
+	}
+
+	dominant := make([]*structField, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		candidates := byKey[fields[i].key]
+		if len(candidates) == 1 {
+			dominant = append(dominant, fields[i])
+			continue
+		}
+
+		var shallowest []*structField
+		for j := 0; j < len(candidates); j++ {
+			if len(shallowest) > 0 && len(candidates[j].index) > len(shallowest[0].index) {
+				continue
+			}
+			if len(shallowest) > 0 && len(candidates[j].index) < len(shallowest[0].index) {
+				shallowest = shallowest[:0]
+			}
+			shallowest = append(shallowest, candidates[j])
+		}
+		var winner *structField
+		if len(shallowest) == 1 {
+			winner = shallowest[0]
+		} else {
+			for j := 0; j < len(shallowest); j++ {
+				if !shallowest[j].named {
+					continue
+				}
+				if winner != nil {
+					winner = nil
+					break
+				}
+				winner = shallowest[j]
+			}
+		}
+		if winner == nil {
+			return nil, errors.Errorf("the key %s is used by more than one field at the same depth", fields[i].key)
+		}
+		if winner == fields[i] {
+			dominant = append(dominant, fields[i])
+		}
+	}
+	return dominant, nil
+}
+
+func appendTypeFields(fields []*structField, t reflect.Type, index []int) ([]*structField, error) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("xlsx")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := parseTag(tag)
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && isEmbeddable(ft) {
+			var err error
+			fields, err = appendTypeFields(fields, ft, fieldIndex)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		field := &structField{
+			index:  fieldIndex,
+			key:    name,
+			header: sf.Name,
+			named:  name != "",
+		}
+		if field.key == "" {
+			field.key = sf.Name
+		}
+		if tagged {
+			err := field.parseOptions(opts)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid xlsx tag on field %s of %s", sf.Name, t)
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// isEmbeddable indicates whether or not the fields of an embedded type are promoted to columns, types written as a
+// single cell, such as time.Time, are not.
+func isEmbeddable(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !t.Implements(valuerType) && !reflect.PtrTo(t).Implements(valuerType)
+}
+
+// parseTag splits a tag into its key and options. Option values can hold commas, as in format=#,##0.00, so a part
+// that's not an option is kept with the previous one.
+func parseTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	var opts []string
+	for i := 1; i < len(parts); i++ {
+		if len(opts) > 0 && !strings.Contains(parts[i], "=") && parts[i] != "omitempty" {
+			opts[len(opts)-1] += "," + parts[i]
+			continue
+		}
+		opts = append(opts, parts[i])
+	}
+	return parts[0], opts
+}
+
+func (f *structField) parseOptions(opts []string) error {
+	for i := 0; i < len(opts); i++ {
+		if opts[i] == "omitempty" {
+			f.omitEmpty = true
+			continue
+		}
+		kv := strings.SplitN(opts[i], "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("unknown option %q", opts[i])
+		}
+		switch kv[0] {
+		case "header":
+			f.header = kv[1]
+		case "format":
+			f.format = kv[1]
+		case "width":
+			width, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil || width < 0 {
+				return errors.Errorf("invalid width %q", kv[1])
+			}
+			f.width = width
+		default:
+			return errors.Errorf("unknown option %q", kv[0])
+		}
+	}
+	return nil
+}
+
+// structType returns the struct type of v, a struct or a pointer to one.
+func structType(t reflect.Type) (reflect.Type, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Wrapf(ErrUnsupportedType, "%s is not a struct", t)
+	}
+	return t, nil
+}
+
+// StructColumns returns the columns of a struct, or a pointer to one, as tagged on its fields. They can be given to
+// DefineColumns, which WriteStruct does on its own if no columns were defined.
+func StructColumns(v interface{}) ([]*WorksheetColumn, error) {
+	if v == nil {
+		return nil, errors.Wrap(ErrUnsupportedType, "can't get the columns of nil")
+	}
+	t, err := structType(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	fields, err := typeFields(t)
+	if err != nil {
+		return nil, err
+	}
+	return fieldsColumns(fields), nil
+}
+
+func fieldsColumns(fields []*structField) []*WorksheetColumn {
+	columns := make([]*WorksheetColumn, len(fields))
+	for i := 0; i < len(fields); i++ {
+		columns[i] = &WorksheetColumn{
+			Key:   fields[i].key,
+			Value: fields[i].header,
+			Width: fields[i].width,
+		}
+		if fields[i].format != "" {
+			columns[i].Style = &Style{NumberFormat: fields[i].format}
+		}
+	}
+	return columns
+}
+
+// WriteStruct adds a row with the fields of v, a struct or a pointer to one, as cells. The columns are defined from its
+// type if no columns were defined yet. Like rows added with AddRow, it must be committed with CommitRows. No row is added
+// if a field can't be written to a cell.
+func (ws *Worksheet) WriteStruct(v interface{}) error {
+	if v == nil {
+		return errors.Wrap(ErrUnsupportedType, "can't write nil")
+	}
+	return ws.writeStruct(reflect.ValueOf(v))
+}
+
+// WriteStructs is just like WriteStruct but writes every element of a slice of structs, or of pointers to them. Rows
+// are committed as they are written.
+func (ws *Worksheet) WriteStructs(slice interface{}) error {
+	s := reflect.ValueOf(slice)
+	if s.Kind() != reflect.Slice && s.Kind() != reflect.Array {
+		return errors.Wrapf(ErrUnsupportedType, "%T is not a slice", slice)
+	}
+
+	f := newFeeder(context.Background(), ws, nil)
+	for i := 0; i < s.Len(); i++ {
+		err := ws.writeStruct(s.Index(i))
+		if err != nil {
+			return errors.Wrapf(err, "failed to write element %d", i)
+		}
+		err = f.added(0)
+		if err != nil {
+			return err
+		}
+	}
+	return f.flush()
+}
+
+func (ws *Worksheet) writeStruct(v reflect.Value) error {
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return errors.Wrap(ErrUnsupportedType, "can't write nil")
+		}
+		v = v.Elem()
+	}
+	t, err := structType(v.Type())
+	if err != nil {
+		return err
+	}
+	fields, err := typeFields(t)
+	if err != nil {
+		return err
+	}
+
+	if ws.columns == nil {
+		err = ws.DefineColumns(fieldsColumns(fields))
+		if err != nil {
+			return errors.Wrap(err, "failed to define the columns")
+		}
+	}
+
+	keys := make([]string, len(fields))
+	values := make([]interface{}, len(fields))
+	for i := 0; i < len(fields); i++ {
+		keys[i] = fields[i].key
+		fv, ok := fieldByIndex(v, fields[i].index)
+		if !ok || (fields[i].omitEmpty && fv.IsZero()) {
+			continue
+		}
+		values[i] = fieldValue(fv)
+	}
+
+	return ws.addValues(keys, values)
+}
+
+// fieldByIndex is just like reflect.Value.FieldByIndex but doesn't panic on nil embedded pointers, it reports the
+// field as missing instead.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i := 0; i < len(index); i++ {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index[i])
+	}
+	return v, true
+}
+
+// fieldValue returns the value of a field as set on a cell, pointers are dereferenced.
+func fieldValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Implements(valuerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
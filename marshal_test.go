@@ -0,0 +1,195 @@
+package xlsx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type auditFields struct {
+	CreatedAt time.Time `xlsx:"created_at,header=Created At,format=yyyy-mm-dd"`
+	internal  string
+}
+
+type order struct {
+	ID     int     `xlsx:"id,header=Order ID,width=12"`
+	Amount float64 `xlsx:"amount,header=Amount,format=#,##0.00"`
+	Note   *string `xlsx:"note,omitempty"`
+	Secret string  `xlsx:"-"`
+	*auditFields
+}
+
+func Test_Worksheet_WriteStructs_ShouldWriteTaggedFields(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-14.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Orders"})
+
+	note := "rush"
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	orders := []*order{
+		&order{ID: 1, Amount: 1234.5, Note: &note, Secret: "x", auditFields: &auditFields{CreatedAt: created}},
+		&order{ID: 2, Amount: 10},
+	}
+	err := ws.WriteStructs(orders)
+	if err != nil {
+		t.Errorf("failed to write the structs: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	content, err := readPart(wb, "xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	for _, expected := range []string{
+		`<col min="1" max="1" width="12" customWidth="1"/><col min="2" max="2" width="9.140625" style="2"/>`,
+		`<row r="1"><c r="A1" t="str"><v>Order ID</v></c><c r="B1" t="str"><v>Amount</v></c><c r="C1" t="str"><v>Note</v></c><c r="D1" t="str"><v>Created At</v></c></row>`,
+		`<c r="C2" t="str"><v>rush</v></c><c r="D2" s="3" t="n"><v>45352.000000</v></c>`,
+		`<row r="3"><c r="A3" s="0" t="n"><v>2</v></c><c r="B3" s="2" t="n"><v>10</v></c></row>`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("worksheet is missing %s, found: %s", expected, content)
+		}
+	}
+
+	content, err = readPart(wb, "xl/styles.xml")
+	if err != nil {
+		t.Errorf("failed to read the styles file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<numFmts count="2"><numFmt numFmtId="164" formatCode="#,##0.00"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd"/></numFmts>`) {
+		t.Errorf("unexpected number formats, found: %s", content)
+	}
+}
+
+func Test_Worksheet_WriteStruct_ShouldNotLeaveARow_WhenValueIsNotSupported(t *testing.T) {
+	type entry struct {
+		Value interface{} `xlsx:"value"`
+	}
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+
+	err := ws.WriteStruct(entry{Value: []int{1}})
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType, found: %v", err)
+	}
+	err = ws.WriteStruct(entry{Value: 5})
+	if err != nil {
+		t.Errorf("failed to write the struct: %v", err)
+		return
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	content, err := readPart(wb, "xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<row r="2"><c r="A2" s="0" t="n"><v>5</v></c></row>`) || strings.Contains(string(content), `<row r="3"`) {
+		t.Errorf("expected the valid row right after the header, found: %s", content)
+	}
+}
+
+type baseRecord struct {
+	ID      int
+	Created string
+}
+
+type idRecord struct {
+	ID int
+}
+
+type labelRecord struct {
+	ID    int `xlsx:"ID"`
+	Label string
+}
+
+func Test_StructColumns_ShouldPromoteShallowestFields(t *testing.T) {
+	type record struct {
+		baseRecord
+		ID int
+	}
+	columns, err := StructColumns(record{})
+	if err != nil {
+		t.Errorf("failed to get the columns: %v", err)
+		return
+	}
+	var keys []string
+	for i := 0; i < len(columns); i++ {
+		keys = append(keys, columns[i].Key)
+	}
+	if strings.Join(keys, ",") != "Created,ID" {
+		t.Errorf("unexpected columns, found: %v", keys)
+	}
+	fields, _ := typeFields(reflect.TypeOf(record{}))
+	if len(fields[1].index) != 1 {
+		t.Errorf("expected the outer ID to win, found the field at %v", fields[1].index)
+	}
+
+	type tagged struct {
+		baseRecord
+		labelRecord
+	}
+	fields, err = typeFields(reflect.TypeOf(tagged{}))
+	if err != nil {
+		t.Errorf("failed to get the fields: %v", err)
+		return
+	}
+	if len(fields) != 3 || fields[1].key != "ID" || fields[1].index[0] != 1 {
+		t.Errorf("expected the tagged ID to win, found: %+v", fields)
+	}
+
+	type ambiguous struct {
+		baseRecord
+		*idRecord
+	}
+	_, err = StructColumns(ambiguous{})
+	if err == nil {
+		t.Error("expected an error when two fields share a key at the same depth")
+	}
+}
+
+func Test_StructColumns_ShouldFail_WhenTagIsInvalid(t *testing.T) {
+	type invalid struct {
+		Amount float64 `xlsx:"amount,width=wide"`
+	}
+	_, err := StructColumns(invalid{})
+	if err == nil {
+		t.Error("expected an error when the width is not a number")
+	}
+
+	type duplicated struct {
+		A int `xlsx:"key"`
+		B int `xlsx:"key"`
+	}
+	_, err = StructColumns(&duplicated{})
+	if err == nil {
+		t.Error("expected an error when two fields share a key")
+	}
+}
@@ -0,0 +1,89 @@
+package xlsx
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// cellRange is a rectangular range of cells with zero based, inclusive coordinates.
+type cellRange struct {
+	x1, y1 int
+	x2, y2 int
+}
+
+func (r *cellRange) String() string {
+	return createRangeFromCoords(r.x1, r.y1, r.x2, r.y2)
+}
+
+func (r *cellRange) overlaps(other *cellRange) bool {
+	return r.x1 <= other.x2 && other.x1 <= r.x2 && r.y1 <= other.y2 && other.y1 <= r.y2
+}
+
+// MergeCells merges the cells in a range such as A1:D1. The range must span at least two cells, be within the worksheet
+// bounds and must not overlap a range merged before.
+func (ws *Worksheet) MergeCells(ref string) error {
+	x1, y1, x2, y2, err := parseRange(ref)
+	if err != nil {
+		return errors.Wrap(err, "can't merge cells")
+	}
+
+	return ws.MergeCellsByCoords(x1, y1, x2, y2)
+}
+
+// MergeCellsByCoords is just like MergeCells but takes the zero based column and row of the top left and bottom right
+// cells of the range.
+func (ws *Worksheet) MergeCellsByCoords(x1, y1, x2, y2 int) error {
+	if ws.committed {
+		return errors.Wrap(ErrCommitted, "can't merge cells on the worksheet")
+	}
+
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+
+	if x1 < 0 || y1 < 0 || x2 >= maxColumns || y2 >= maxRows {
+		return errors.Errorf("can't merge cells outside of the worksheet bounds, columns must be within 0 and %d and rows within 0 and %d", maxColumns-1, maxRows-1)
+	}
+
+	r := &cellRange{x1: x1, y1: y1, x2: x2, y2: y2}
+	if x1 == x2 && y1 == y2 {
+		return errors.Errorf("can't merge the single cell range %s", r)
+	}
+
+	for i := 0; i < len(ws.mergedCells); i++ {
+		if ws.mergedCells[i].overlaps(r) {
+			return errors.Errorf("can't merge %s as it overlaps the merged range %s", r, ws.mergedCells[i])
+		}
+	}
+
+	ws.mergedCells = append(ws.mergedCells, r)
+
+	return nil
+}
+
+func (ws *Worksheet) writeMergeCells(f *os.File) error {
+	if len(ws.mergedCells) == 0 {
+		return nil
+	}
+
+	_, err := f.WriteString(startMergeCellsFormat(len(ws.mergedCells)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_MERGE_CELLS to file %s", ws.filePath)
+	}
+	for i := 0; i < len(ws.mergedCells); i++ {
+		_, err = f.WriteString(mergeCellFormat(ws.mergedCells[i].String()))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append a merged cell range to file %s", ws.filePath)
+		}
+	}
+	_, err = f.WriteString(endMergeCells)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_MERGE_CELLS to file %s", ws.filePath)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package xlsx
+
+import (
+	"testing"
+)
+
+func Test_Worksheet_MergeCells_ShouldCollectRanges_WhenGivenValidRanges(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Data",
+	})
+
+	err := ws.MergeCells("A1:D1")
+	if err != nil {
+		t.Errorf("failed to merge cells: %v", err)
+		return
+	}
+	err = ws.MergeCellsByCoords(0, 1, 1, 2)
+	if err != nil {
+		t.Errorf("failed to merge cells: %v", err)
+		return
+	}
+	err = ws.MergeCells("f6:g7")
+	if err != nil {
+		t.Errorf("failed to merge cells: %v", err)
+		return
+	}
+
+	if len(ws.mergedCells) != 3 || ws.mergedCells[0].String() != "A1:D1" || ws.mergedCells[1].String() != "A2:B3" || ws.mergedCells[2].String() != "F6:G7" {
+		t.Errorf("unexpected merged cells, found: %v", ws.mergedCells)
+	}
+}
+
+func Test_Worksheet_MergeCells_ShouldFail_WhenGivenInvalidRanges(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Data",
+	})
+
+	err := ws.MergeCells("B2:D4")
+	if err != nil {
+		t.Errorf("failed to merge cells: %v", err)
+		return
+	}
+
+	for _, ref := range []string{"C3:E5", "A1:A1", "A0:B1", "A1", "A1:XFE1"} {
+		err = ws.MergeCells(ref)
+		if err == nil {
+			t.Errorf("expected an error when merging %s", ref)
+		}
+	}
+}
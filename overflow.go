@@ -0,0 +1,68 @@
+package xlsx
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// overflow adds the worksheet that takes the rows once this one is full. It's named after the first worksheet of the
+// chain, as in "Data (2)", and repeats the header written by DefineColumns.
+func (ws *Worksheet) overflow() (*Worksheet, error) {
+	origin, part := ws, 1
+	if ws.origin != nil {
+		origin, part = ws.origin, ws.part
+	}
+
+	// The name is picked while holding the lock so another worksheet can't take it before it's added.
+	wb := ws.workbook
+	wb.mu.Lock()
+	var name string
+	for n := part + 1; name == "" || wb.hasWorksheet(name); n++ {
+		name = overflowName(origin.name, n)
+		part = n
+	}
+
+	opts := origin.options
+	opts.Name = name
+	if opts.AsTable != nil && opts.AsTable.Name != "" {
+		table := *opts.AsTable
+		table.Name = fmt.Sprintf("%s_%d", table.Name, part)
+		opts.AsTable = &table
+	}
+	next, err := wb.addWorksheet(&opts)
+	wb.mu.Unlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add the overflow worksheet")
+	}
+	next.origin = origin
+	next.part = part
+	next.rowsLimit = ws.rowsLimit
+
+	for i := 0; i < len(origin.conditionalFormats); i++ {
+		if origin.conditionalFormats[i].columnKey != "" {
+			next.conditionalFormats = append(next.conditionalFormats, origin.conditionalFormats[i])
+		}
+	}
+
+	if origin.columns != nil {
+		err = next.DefineColumns(origin.columns)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to repeat the header on the overflow worksheet")
+		}
+	}
+
+	ws.next = next
+
+	return next, nil
+}
+
+// overflowName appends the part number to the name, shortening it if needed to fit the worksheet name limit.
+func overflowName(name string, part int) string {
+	suffix := fmt.Sprintf(" (%d)", part)
+	runes := []rune(name)
+	if max := maxWorksheetNameLength - len(suffix); len(runes) > max {
+		runes = runes[:max]
+	}
+	return string(runes) + suffix
+}
@@ -0,0 +1,132 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Worksheet_AddRow_ShouldFail_WhenRowsLimitIsReached(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.rowsLimit = 2
+
+	ws.AddRow()
+	ws.AddRow()
+	_, err := ws.AddRow()
+
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Errorf("expected a LimitError, found: %v", err)
+		return
+	}
+	if limitErr.Limit != RowsLimit || limitErr.Sheet != "Data" {
+		t.Errorf("unexpected limit error: %v", limitErr)
+	}
+}
+
+func Test_Worksheet_CommitRows_ShouldFail_WhenCellIsTooLong(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = strings.Repeat("a", maxCellLength+1)
+
+	err := ws.CommitRows()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != CellLengthLimit {
+		t.Errorf("expected a cell length LimitError, found: %v", err)
+	}
+}
+
+func Test_Row_AddCell_ShouldFail_WhenColumnsLimitIsReached(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	row, _ := ws.AddRow()
+	for i := 0; i < maxColumns; i++ {
+		_, err := row.AddCell()
+		if err != nil {
+			t.Errorf("failed to add cell %d: %v", i, err)
+			return
+		}
+	}
+
+	_, err := row.AddCell()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != ColumnsLimit {
+		t.Errorf("expected a columns LimitError, found: %v", err)
+	}
+}
+
+func Test_Worksheet_AddRow_ShouldContinueOnNewWorksheet_WhenOverflowIsSet(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-13.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:     "Data",
+		Overflow: true,
+	})
+	ws.rowsLimit = 3
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	for i := 0; i < 6; i++ {
+		row, err := ws.AddRow()
+		if err != nil {
+			t.Errorf("failed to add row %d: %v", i, err)
+			return
+		}
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = i
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	if len(wb.worksheets) != 3 {
+		t.Errorf("expected 3 worksheets, found %d", len(wb.worksheets))
+		return
+	}
+	for i, name := range []string{"Data", "Data (2)", "Data (3)"} {
+		if wb.worksheets[i].name != name {
+			t.Errorf("unexpected worksheet name, expected %q, found %q", name, wb.worksheets[i].name)
+		}
+	}
+
+	content, err := ioutil.ReadFile(wb.worksheets[1].filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	expected := `<sheetData><row r="1"><c r="A1" t="str"><v>ID</v></c></row><row r="2"><c r="A2" s="0" t="n"><v>2</v></c></row><row r="3"><c r="A3" s="0" t="n"><v>3</v></c></row></sheetData>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected overflow worksheet content, found: %s", content)
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
+
+func Test_overflowName_ShouldFitWorksheetNameLimit(t *testing.T) {
+	name := overflowName("A name that is exactly 31 chars", 2)
+	if name != "A name that is exactly 31 c (2)" {
+		t.Errorf("unexpected overflow name, found %q", name)
+	}
+}
@@ -0,0 +1,163 @@
+package xlsx
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Page orientations.
+const (
+	PortraitOrientation  = "portrait"
+	LandscapeOrientation = "landscape"
+)
+
+// Common paper sizes, any other paper size code from the SpreadsheetML specification can be used.
+const (
+	PaperLetter = 1
+	PaperLegal  = 5
+	PaperA3     = 8
+	PaperA4     = 9
+)
+
+// PageSetup has options used when printing a worksheet.
+type PageSetup struct {
+	// Orientation is either portrait, the default, or landscape.
+	Orientation string
+	// PaperSize is a paper size code such as PaperA4, it defaults to the printer's paper.
+	PaperSize int
+	// FitToWidth scales the worksheet to be this many pages wide, and as many pages tall as needed.
+	FitToWidth int
+	// Margins are in inches, Excel's normal margins are used when it's nil.
+	Margins *PageMargins
+	// Gridlines prints the cell gridlines.
+	Gridlines bool
+	// Header and Footer are printed on every page. They may use Excel's codes, like &P of &N for the page number and
+	// count, &D for the date or &L, &C and &R for the left, center and right sections.
+	Header string
+	Footer string
+	// PrintTitles repeats the header written by DefineColumns at the top of every page.
+	PrintTitles bool
+	// PrintArea is a range, such as A1:F50, that limits what is printed.
+	PrintArea string
+}
+
+// PageMargins are the margins of a printed page, in inches.
+type PageMargins struct {
+	Left   float64
+	Right  float64
+	Top    float64
+	Bottom float64
+	Header float64
+	Footer float64
+}
+
+var defaultPageMargins = PageMargins{Left: 0.7, Right: 0.7, Top: 0.75, Bottom: 0.75, Header: 0.3, Footer: 0.3}
+
+func validatePageSetup(p *PageSetup) error {
+	switch p.Orientation {
+	case "", PortraitOrientation, LandscapeOrientation:
+	default:
+		return errors.Errorf("unknown orientation %q", p.Orientation)
+	}
+
+	if p.PaperSize < 0 {
+		return errors.New("the paper size can't be negative")
+	}
+
+	if p.FitToWidth < 0 {
+		return errors.New("the number of pages to fit the width into can't be negative")
+	}
+
+	if p.Margins != nil {
+		m := p.Margins
+		if m.Left < 0 || m.Right < 0 || m.Top < 0 || m.Bottom < 0 || m.Header < 0 || m.Footer < 0 {
+			return errors.New("margins can't be negative")
+		}
+	}
+
+	if p.PrintArea != "" {
+		_, _, _, _, err := parseRange(p.PrintArea)
+		if err != nil {
+			return errors.Wrap(err, "invalid print area")
+		}
+	}
+
+	return nil
+}
+
+func (ws *Worksheet) writePageSetup(f *os.File) error {
+	p := ws.options.PageSetup
+	if p == nil {
+		return nil
+	}
+
+	if p.Gridlines {
+		_, err := f.WriteString(printOptionsFormat(p.Gridlines))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the print options to file %s", ws.filePath)
+		}
+	}
+
+	m := defaultPageMargins
+	if p.Margins != nil {
+		m = *p.Margins
+	}
+	_, err := f.WriteString(pageMarginsFormat(m.Left, m.Right, m.Top, m.Bottom, m.Header, m.Footer))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the page margins to file %s", ws.filePath)
+	}
+
+	var attrs string
+	if p.PaperSize > 0 {
+		attrs += fmt.Sprintf(` paperSize="%d"`, p.PaperSize)
+	}
+	if p.Orientation != "" {
+		attrs += fmt.Sprintf(` orientation="%s"`, p.Orientation)
+	}
+	if p.FitToWidth > 0 {
+		attrs += fmt.Sprintf(` fitToWidth="%d" fitToHeight="0"`, p.FitToWidth)
+	}
+	if attrs != "" {
+		_, err = f.WriteString(pageSetupFormat(attrs))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the page setup to file %s", ws.filePath)
+		}
+	}
+
+	if p.Header != "" || p.Footer != "" {
+		_, err = f.WriteString(headerFooterFormat(p.Header, p.Footer))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the header and footer to file %s", ws.filePath)
+		}
+	}
+
+	return nil
+}
+
+// printNames are the built-in defined names required by the page setup of the worksheet at the given index.
+func (ws *Worksheet) printNames(index int) []*definedName {
+	p := ws.options.PageSetup
+	if p == nil {
+		return nil
+	}
+
+	var names []*definedName
+	if p.PrintArea != "" {
+		x1, y1, x2, y2, _ := parseRange(p.PrintArea)
+		names = append(names, &definedName{
+			name:         "_xlnm.Print_Area",
+			refersTo:     createAbsoluteRangeFromCoords(ws.name, x1, y1, x2, y2),
+			localSheetID: index,
+		})
+	}
+	if p.PrintTitles && ws.headerRows > 0 {
+		names = append(names, &definedName{
+			name:         "_xlnm.Print_Titles",
+			refersTo:     fmt.Sprintf("%s!$1:$%d", quoteSheetName(ws.name), ws.headerRows),
+			localSheetID: index,
+		})
+	}
+	return names
+}
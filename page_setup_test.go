@@ -0,0 +1,59 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func Test_Worksheet_Commit_ShouldWritePageSetup_WhenPageSetupIsSet(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-10.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Ops Report",
+		PageSetup: &PageSetup{
+			Orientation: LandscapeOrientation,
+			PaperSize:   PaperA4,
+			FitToWidth:  1,
+			Gridlines:   true,
+			Footer:      "&CPage &P of &N",
+			PrintTitles: true,
+			PrintArea:   "A1:B20",
+		},
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+		&WorksheetColumn{Key: "name", Value: "Name"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	expected := `<printOptions gridLines="1"/><pageMargins left="0.7" right="0.7" top="0.75" bottom="0.75" header="0.3" footer="0.3"/><pageSetup paperSize="9" orientation="landscape" fitToWidth="1" fitToHeight="0"/><headerFooter><oddFooter>&amp;CPage &amp;P of &amp;N</oddFooter></headerFooter>`
+	for _, e := range []string{expected, `<sheetPr><pageSetUpPr fitToPage="1"/></sheetPr>`} {
+		if !strings.Contains(string(content), e) {
+			t.Errorf("worksheet is missing %s, found: %s", e, content)
+		}
+	}
+
+	names := wb.definedNames()
+	if len(names) != 2 || names[0].refersTo != "'Ops Report'!$A$1:$B$20" || names[1].name != "_xlnm.Print_Titles" || names[1].refersTo != "'Ops Report'!$1:$1" {
+		t.Errorf("unexpected defined names, found: %v", names)
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
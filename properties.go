@@ -0,0 +1,205 @@
+package xlsx
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultApplication = "Microsoft Excel"
+
+// DocumentProperties are the properties written to the docProps parts of the workbook.
+type DocumentProperties struct {
+	Title       string
+	Subject     string
+	Creator     string
+	Keywords    string
+	Description string
+	Category    string
+	// Created and Modified default to the moment the workbook is committed.
+	Created  time.Time
+	Modified time.Time
+	// Application is the name of the application that created the workbook, Microsoft Excel by default.
+	Application string
+	// Custom are written to docProps/custom.xml in the given order.
+	Custom []*CustomProperty
+}
+
+// CustomProperty is a user defined document property. Its value may be a string, a bool, an integer, a float or a
+// time.Time.
+type CustomProperty struct {
+	Name  string
+	Value interface{}
+}
+
+func (wb *Workbook) hasCustomProperties() bool {
+	return wb.options.Properties != nil && len(wb.options.Properties.Custom) > 0
+}
+
+func (wb *Workbook) createDocumentProperties() error {
+	props := wb.options.Properties
+	if props == nil {
+		props = &DocumentProperties{}
+	}
+
+	err := wb.createCoreProperties(props)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the core properties file")
+	}
+
+	application := props.Application
+	if application == "" {
+		application = defaultApplication
+	}
+	filePath := path.Join(wb.tempDirs.docProps, "app.xml")
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(appPropsFormat(application))
+	if err != nil {
+		return errors.Wrapf(err, "failed to write to %s", filePath)
+	}
+
+	if wb.hasCustomProperties() {
+		err = wb.createCustomProperties(props.Custom)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the custom properties file")
+		}
+	}
+
+	return nil
+}
+
+func (wb *Workbook) createCoreProperties(props *DocumentProperties) error {
+	filePath := path.Join(wb.tempDirs.docProps, "core.xml")
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", filePath)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	created, modified := props.Created, props.Modified
+	if created.IsZero() {
+		created = now
+	}
+	if modified.IsZero() {
+		modified = now
+	}
+
+	content := startCoreProps
+	elements := []struct {
+		name  string
+		value string
+	}{
+		{"dc:title", props.Title},
+		{"dc:subject", props.Subject},
+		{"dc:creator", props.Creator},
+		{"cp:keywords", props.Keywords},
+		{"dc:description", props.Description},
+		{"cp:category", props.Category},
+	}
+	for _, e := range elements {
+		if e.value != "" {
+			content += corePropFormat(e.name, e.value)
+		}
+	}
+	content += corePropDateFormat("dcterms:created", created)
+	content += corePropDateFormat("dcterms:modified", modified)
+	content += endCoreProps
+
+	_, err = f.WriteString(content)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write to %s", filePath)
+	}
+
+	return nil
+}
+
+func (wb *Workbook) createCustomProperties(custom []*CustomProperty) error {
+	filePath := path.Join(wb.tempDirs.docProps, "custom.xml")
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(startCustomProps)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_CUSTOM_PROPS to %s", filePath)
+	}
+
+	names := make(map[string]bool, len(custom))
+	for i := 0; i < len(custom); i++ {
+		p := custom[i]
+		if p.Name == "" {
+			return errors.New("custom properties need a name")
+		}
+		if names[p.Name] {
+			return errors.Errorf("the custom property %s is defined more than once", p.Name)
+		}
+		names[p.Name] = true
+
+		valueType, value, err := customPropertyValue(p.Value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for the custom property %s", p.Name)
+		}
+
+		// Property ids start at 2, 0 and 1 are reserved.
+		_, err = f.WriteString(customPropFormat(i+2, p.Name, valueType, value))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the custom property %s to %s", p.Name, filePath)
+		}
+	}
+
+	_, err = f.WriteString(endCustomProps)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_CUSTOM_PROPS to %s", filePath)
+	}
+
+	return nil
+}
+
+// customPropertyValue returns the variant type and the text of a custom property value.
+func customPropertyValue(v interface{}) (string, string, error) {
+	switch value := v.(type) {
+	case string:
+		return "lpwstr", value, nil
+	case bool:
+		return "bool", strconv.FormatBool(value), nil
+	case time.Time:
+		return "filetime", value.UTC().Format(time.RFC3339), nil
+	}
+
+	if v == nil {
+		return "", "", errors.New("value can't be nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		if n < -1<<31 || n > 1<<31-1 {
+			return "r8", fmt.Sprint(n), nil
+		}
+		return "i4", fmt.Sprint(n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := rv.Uint()
+		if n > 1<<31-1 {
+			return "r8", fmt.Sprint(n), nil
+		}
+		return "i4", fmt.Sprint(n), nil
+	case reflect.Float32, reflect.Float64:
+		return "r8", strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	}
+
+	return "", "", errors.Wrapf(ErrUnsupportedType, "%T is not supported as a custom property value", v)
+}
@@ -0,0 +1,79 @@
+package xlsx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Workbook_Commit_ShouldWriteDocumentProperties_WhenPropertiesAreSet(t *testing.T) {
+	created := time.Date(2019, 4, 27, 10, 30, 0, 0, time.UTC)
+	wb := NewWorkbookWithOptions("./spreadsheet-12.xlsx", &WorkbookOptions{
+		Properties: &DocumentProperties{
+			Title:   "Orders & Returns",
+			Creator: "Reporting",
+			Created: created,
+			Custom: []*CustomProperty{
+				&CustomProperty{Name: "Department", Value: "Finance"},
+				&CustomProperty{Name: "Revision", Value: 3},
+				&CustomProperty{Name: "Final", Value: true},
+			},
+		},
+	})
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Data",
+	})
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = "test"
+	err := ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	files := map[string][]string{
+		"docProps/core.xml": []string{
+			`<dc:title>Orders &amp; Returns</dc:title><dc:creator>Reporting</dc:creator>`,
+			`<dcterms:created xsi:type="dcterms:W3CDTF">2019-04-27T10:30:00Z</dcterms:created>`,
+		},
+		"docProps/app.xml": []string{
+			`<Application>Microsoft Excel</Application>`,
+		},
+		"docProps/custom.xml": []string{
+			`pid="2" name="Department"><vt:lpwstr>Finance</vt:lpwstr></property>`,
+			`pid="3" name="Revision"><vt:i4>3</vt:i4></property>`,
+			`pid="4" name="Final"><vt:bool>true</vt:bool></property>`,
+		},
+		"_rels/.rels": []string{
+			`Target="docProps/core.xml"`,
+			`Target="docProps/custom.xml"`,
+		},
+		"[Content_Types].xml": []string{
+			`<Override PartName="/docProps/custom.xml"`,
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`,
+		},
+	}
+	for name, expectations := range files {
+		content, err := readPart(wb, name)
+		if err != nil {
+			t.Errorf("failed to read %s: %v", name, err)
+			continue
+		}
+		for _, expected := range expectations {
+			if !strings.Contains(string(content), expected) {
+				t.Errorf("%s is missing %s, found: %s", name, expected, content)
+			}
+		}
+	}
+}
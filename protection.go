@@ -0,0 +1,177 @@
+package xlsx
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultSpinCount = 100000
+	saltLength       = 16
+)
+
+// SheetProtection has options used when protecting a worksheet. Only cells whose style unlocks them can be edited,
+// the actions Excel blocks by default can be allowed with the Allow flags.
+type SheetProtection struct {
+	// Password is optional, without it users can unprotect the worksheet from Excel.
+	Password string
+	// SpinCount is the number of hashing iterations, 100000 by default.
+	SpinCount int
+
+	PreventSelectLockedCells   bool
+	PreventSelectUnlockedCells bool
+	AllowFormatCells           bool
+	AllowFormatColumns         bool
+	AllowFormatRows            bool
+	AllowInsertColumns         bool
+	AllowInsertRows            bool
+	AllowInsertHyperlinks      bool
+	AllowDeleteColumns         bool
+	AllowDeleteRows            bool
+	AllowSort                  bool
+	AllowAutoFilter            bool
+	AllowPivotTables           bool
+}
+
+// WorkbookProtection has options used when protecting a workbook.
+type WorkbookProtection struct {
+	// Password is optional, without it users can unprotect the workbook from Excel.
+	Password string
+	// SpinCount is the number of hashing iterations, 100000 by default.
+	SpinCount int
+	// LockStructure prevents worksheets from being added, removed, renamed, moved, hidden or unhidden.
+	LockStructure bool
+	// LockWindows prevents the workbook window from being moved or resized.
+	LockWindows bool
+}
+
+type passwordHash struct {
+	algorithmName string
+	hashValue     string
+	saltValue     string
+	spinCount     int
+}
+
+// hashPassword hashes a password as described by ECMA-376 for sheetProtection and workbookProtection, that is, the
+// SHA-512 of a random salt followed by the UTF-16LE password, rehashed spinCount times with the little-endian
+// iteration number appended.
+func hashPassword(password string, spinCount int) (*passwordHash, error) {
+	if spinCount <= 0 {
+		spinCount = defaultSpinCount
+	}
+
+	salt := make([]byte, saltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate a salt")
+	}
+
+	return &passwordHash{
+		algorithmName: "SHA-512",
+		hashValue:     base64.StdEncoding.EncodeToString(hashPasswordWithSalt(password, salt, spinCount)),
+		saltValue:     base64.StdEncoding.EncodeToString(salt),
+		spinCount:     spinCount,
+	}, nil
+}
+
+func hashPasswordWithSalt(password string, salt []byte, spinCount int) []byte {
+	encoded := utf16.Encode([]rune(password))
+	input := make([]byte, len(salt), len(salt)+len(encoded)*2)
+	copy(input, salt)
+	for _, c := range encoded {
+		input = append(input, byte(c), byte(c>>8))
+	}
+	sum := sha512.Sum512(input)
+
+	buf := make([]byte, sha512.Size+4)
+	for i := 0; i < spinCount; i++ {
+		copy(buf, sum[:])
+		binary.LittleEndian.PutUint32(buf[sha512.Size:], uint32(i))
+		sum = sha512.Sum512(buf)
+	}
+
+	return sum[:]
+}
+
+func (ws *Worksheet) writeSheetProtection(f *os.File) error {
+	p := ws.options.Protection
+	if p == nil {
+		return nil
+	}
+
+	attrs := ""
+	if p.Password != "" {
+		hash, err := hashPassword(p.Password, p.SpinCount)
+		if err != nil {
+			return errors.Wrap(err, "failed to hash the worksheet password")
+		}
+		attrs += fmt.Sprintf(` algorithmName="%s" hashValue="%s" saltValue="%s" spinCount="%d"`, hash.algorithmName, hash.hashValue, hash.saltValue, hash.spinCount)
+	}
+	attrs += ` sheet="1" objects="1" scenarios="1"`
+
+	// The flags are set when the action is prevented.
+	flags := []struct {
+		name      string
+		prevented bool
+	}{
+		{"formatCells", !p.AllowFormatCells},
+		{"formatColumns", !p.AllowFormatColumns},
+		{"formatRows", !p.AllowFormatRows},
+		{"insertColumns", !p.AllowInsertColumns},
+		{"insertRows", !p.AllowInsertRows},
+		{"insertHyperlinks", !p.AllowInsertHyperlinks},
+		{"deleteColumns", !p.AllowDeleteColumns},
+		{"deleteRows", !p.AllowDeleteRows},
+		{"selectLockedCells", p.PreventSelectLockedCells},
+		{"sort", !p.AllowSort},
+		{"autoFilter", !p.AllowAutoFilter},
+		{"pivotTables", !p.AllowPivotTables},
+		{"selectUnlockedCells", p.PreventSelectUnlockedCells},
+	}
+	for _, flag := range flags {
+		attrs += fmt.Sprintf(` %s="%d"`, flag.name, boolToInt(flag.prevented))
+	}
+
+	_, err := f.WriteString(sheetProtectionFormat(attrs))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the sheet protection to file %s", ws.filePath)
+	}
+
+	return nil
+}
+
+// Protect protects the workbook structure or windows, replacing any previous protection.
+func (wb *Workbook) Protect(p *WorkbookProtection) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if wb.committed {
+		return errors.Wrap(ErrCommitted, "can't protect the workbook")
+	}
+
+	attrs := ""
+	if p.Password != "" {
+		hash, err := hashPassword(p.Password, p.SpinCount)
+		if err != nil {
+			return errors.Wrap(err, "failed to hash the workbook password")
+		}
+		attrs += fmt.Sprintf(` workbookAlgorithmName="%s" workbookHashValue="%s" workbookSaltValue="%s" workbookSpinCount="%d"`, hash.algorithmName, hash.hashValue, hash.saltValue, hash.spinCount)
+	}
+	if p.LockStructure {
+		attrs += ` lockStructure="1"`
+	}
+	if p.LockWindows {
+		attrs += ` lockWindows="1"`
+	}
+
+	wb.protection = workbookProtectionFormat(attrs)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package xlsx
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func Test_hashPasswordWithSalt_ShouldFollowTheSpecification(t *testing.T) {
+	salt := make([]byte, saltLength)
+	for i := range salt {
+		salt[i] = byte(i)
+	}
+
+	hash := base64.StdEncoding.EncodeToString(hashPasswordWithSalt("secret", salt, 1000))
+
+	expected := "beRsOEW5hgnfGpC0r+gdvIpkWH0Z1MMQdzvbTFscKwUs/P8cgikVLIjRJliVxPNFIY8IKWPw9fTenrNFQcPUYQ=="
+	if hash != expected {
+		t.Errorf("hash differs from the expected, found: %s, expected: %s", hash, expected)
+	}
+}
+
+func Test_Worksheet_Commit_ShouldUnlockDataCells_WhenWorksheetIsProtected(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-9.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Template",
+		Protection: &SheetProtection{
+			Password:        "secret",
+			SpinCount:       10,
+			AllowAutoFilter: true,
+		},
+	})
+
+	unlocked := &Style{Protection: &CellProtection{Locked: false}}
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+		&WorksheetColumn{Key: "status", Value: "Status", Style: unlocked},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCellWithKey("id")
+	cell.Value = 1
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	err = wb.Protect(&WorkbookProtection{Password: "secret", SpinCount: 10, LockStructure: true})
+	if err != nil {
+		t.Errorf("failed to protect the workbook: %v", err)
+		return
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	content, err := readPart(wb, "xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	for _, expected := range []string{
+		`<cols><col min="2" max="2" width="9.140625" style="2"/></cols>`,
+		`<c r="B1" t="str"><v>Status</v></c>`,
+		`<row r="2"><c r="A2" s="0" t="n"><v>1</v></c></row>`,
+		`algorithmName="SHA-512"`,
+		`spinCount="10" sheet="1" objects="1" scenarios="1" formatCells="1"`,
+		`autoFilter="0"`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("worksheet is missing %s, found: %s", expected, content)
+		}
+	}
+
+	content, err = readPart(wb, "xl/styles.xml")
+	if err != nil {
+		t.Errorf("failed to read the styles file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<cellXfs count="3">`) || !strings.Contains(string(content), `applyProtection="1"><protection locked="0" hidden="0"/></xf>`) {
+		t.Errorf("styles are missing the unlocked cell format, found: %s", content)
+	}
+
+	content, err = readPart(wb, "xl/workbook.xml")
+	if err != nil {
+		t.Errorf("failed to read the workbook file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `workbookSpinCount="10" lockStructure="1"/><bookViews>`) {
+		t.Errorf("workbook is missing the protection, found: %s", content)
+	}
+}
@@ -6,11 +6,25 @@ import (
 
 // Row represents a row in a worksheet. A worksheet has a collection of rows.
 type Row struct {
-	worksheet *Worksheet
-	index     int
-	cells     []*Cell
-	cellsMap  map[string]*Cell
-	committed bool
+	worksheet    *Worksheet
+	index        int
+	cells        []*Cell
+	cellsMap     map[string]*Cell
+	committed    bool
+	outlineLevel int
+	collapsed    bool
+	hidden       bool
+	// raw has the cells already rendered by a TypedWorksheet, they are written as is.
+	raw []byte
+}
+
+// RowOptions has options used when creating a new row.
+type RowOptions struct {
+	// OutlineLevel groups the row with its neighbours at the same level, from 0 to the MaxOutlineLevel of the worksheet.
+	OutlineLevel int
+	// Collapsed marks the row as the summary of a collapsed group.
+	Collapsed bool
+	Hidden    bool
 }
 
 // CellOptions has options used when creating a new cell.
@@ -19,17 +33,50 @@ type CellOptions struct {
 	Value interface{}
 }
 
-// AddCell adds a new cell to the row.
+// AddCell adds a new cell to the row, right after the last one.
 func (r *Row) AddCell() (*Cell, error) {
+	cellIndex := 0
+	if len(r.cells) > 0 {
+		cellIndex = r.cells[len(r.cells)-1].index + 1
+	}
+	return r.addCell(cellIndex)
+}
+
+// SetCell adds a new cell to the row at the zero based column, the columns skipped are left empty. Cells must be added
+// from left to right.
+func (r *Row) SetCell(col int) (*Cell, error) {
+	return r.addCell(col)
+}
+
+// SetCellByRef is just like SetCell but takes the column letters, such as "F" or "f".
+func (r *Row) SetCellByRef(ref string) (*Cell, error) {
+	col, err := lettersToNumeric(ref)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't add the cell")
+	}
+	return r.addCell(col)
+}
+
+func (r *Row) addCell(cellIndex int) (*Cell, error) {
 	if r.committed {
-		return nil, errors.New("can't add cells to a committed row")
+		return nil, errors.Wrap(ErrCommitted, "can't add cells to the row")
 	}
 
 	if r.worksheet.columns != nil {
-		return nil, errors.New("can't add cells without keys to this worksheet as columns were defined")
+		return nil, errors.Wrap(ErrColumnsDefined, "can't add cells without keys")
+	}
+
+	if cellIndex < 0 {
+		return nil, errors.Errorf("the column can't be negative, found %d", cellIndex)
+	}
+	if cellIndex >= maxColumns {
+		return nil, &LimitError{Sheet: r.worksheet.name, Limit: ColumnsLimit, Max: maxColumns}
+	}
+	if len(r.cells) > 0 && cellIndex <= r.cells[len(r.cells)-1].index {
+		last := r.cells[len(r.cells)-1]
+		return nil, errors.Wrapf(ErrOutOfOrder, "can't add cell %s after cell %s", createIdentifierFromCoords(cellIndex, r.index), last.identifier)
 	}
 
-	cellIndex := len(r.cells)
 	cell := &Cell{
 		row:        r,
 		index:      cellIndex,
@@ -44,24 +91,16 @@ func (r *Row) AddCell() (*Cell, error) {
 // AddCellWithKey is just like AddCell but adds a key to the cell. Should be used if columns were defined on the worksheet.
 func (r *Row) AddCellWithKey(key string) (*Cell, error) {
 	if r.committed {
-		return nil, errors.New("can't add cells to a committed row")
+		return nil, errors.Wrap(ErrCommitted, "can't add cells to the row")
 	}
 
 	if r.worksheet.columns == nil {
-		return nil, errors.New("can't add cells with keys if no columns were defined")
+		return nil, errors.Wrap(ErrNoColumns, "can't add cells with keys")
 	}
 
-	var cellIndex int
-	var found bool
-	for i := 0; i < len(r.worksheet.columns); i++ {
-		if r.worksheet.columns[i].Key == key {
-			cellIndex = i
-			found = true
-			break
-		}
-	}
-	if !found {
-		return nil, errors.Errorf("undefined column named %s", key)
+	cellIndex := r.worksheet.columnIndex(key)
+	if cellIndex < 0 {
+		return nil, &CellError{Sheet: r.worksheet.name, Row: r.index + 1, Key: key, Err: ErrUnknownColumn}
 	}
 
 	cell := &Cell{
@@ -75,3 +114,26 @@ func (r *Row) AddCellWithKey(key string) (*Cell, error) {
 
 	return cell, nil
 }
+
+// AddChildRow adds a new row to the worksheet one outline level deeper than this one, so it's grouped under it. Child
+// rows of a collapsed or hidden row are hidden. Use it right after this row or its last descendant, with
+// WorksheetOptions.SummaryRowsAbove set, to stream a parent row followed by its children.
+func (r *Row) AddChildRow() (*Row, error) {
+	return r.worksheet.AddRowWithOptions(&RowOptions{
+		OutlineLevel: r.outlineLevel + 1,
+		Hidden:       r.collapsed || r.hidden,
+	})
+}
+
+// discard removes the row from the pending rows of its worksheet, so the next row takes its place. Only the last row
+// added can be discarded.
+func (r *Row) discard() {
+	ws := r.worksheet
+	last := len(ws.pendingRows) - 1
+	if last < 0 || ws.pendingRows[last] != r {
+		return
+	}
+	ws.pendingRows[last] = nil
+	ws.pendingRows = ws.pendingRows[:last]
+	ws.rowsCount = r.index
+}
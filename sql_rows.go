@@ -0,0 +1,263 @@
+package xlsx
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SQLRowsOptions has options used when writing the rows of a query.
+type SQLRowsOptions struct {
+	// Columns selects, orders and renames the columns of the query: the Key of each column is the name of a query
+	// column and its Value the header. Every query column is written, with its name as header, when it's empty.
+	Columns []*WorksheetColumn
+	// BatchSize is the number of rows kept in memory before committing them, 1000 by default. As with BatchOptions,
+	// they are also committed once they take roughly 4 MiB.
+	BatchSize int
+}
+
+// sqlEncoding is how the values of a query column are written.
+type sqlEncoding int
+
+const (
+	sqlText sqlEncoding = iota
+	sqlInteger
+	sqlUnsigned
+	sqlFloat
+	sqlDecimal
+	sqlDate
+	sqlDateText
+)
+
+// decimalPattern matches the decimal numbers written as they are, rather than through a float64.
+var decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// decimalText is a decimal number, such as a DECIMAL value, written to a number cell with all of its digits.
+type decimalText string
+
+// nullDecimal is scanned from a DECIMAL or NUMERIC column as text, so its value isn't rounded.
+type nullDecimal struct {
+	sql.NullString
+}
+
+// nullDateText is scanned from a date column its driver returns as text, such as MySQL without parseTime.
+type nullDateText struct {
+	sql.NullString
+}
+
+// dateLayouts are the layouts the dates returned as text are parsed with, those that match none are written as text.
+var dateLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+// nullUnsigned is scanned from an unsigned integer column as text, as its values may not fit in an int64.
+type nullUnsigned struct {
+	sql.NullString
+}
+
+// WriteSQLRows writes every row of a query, committing them as they are read. The columns are defined from the query
+// if no columns were defined yet, and their types pick whether the values are written as numbers, dates or text.
+// DECIMAL and NUMERIC values are written with all of their digits, which Excel rounds to 15 significant digits, and
+// unsigned integers larger than 2^53 are written as text. Dates the driver returns as text are parsed, those that can't
+// be are written as text. The rows are not closed.
+func (ws *Worksheet) WriteSQLRows(rows *sql.Rows, opts *SQLRowsOptions) error {
+	if opts == nil {
+		opts = &SQLRowsOptions{}
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the column types")
+	}
+	indexes := make(map[string]int, len(types))
+	for i := len(types) - 1; i >= 0; i-- {
+		indexes[types[i].Name()] = i
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		for i := 0; i < len(types); i++ {
+			columns = append(columns, &WorksheetColumn{Key: types[i].Name(), Value: types[i].Name()})
+		}
+	}
+	sources := make([]int, len(columns))
+	for i := 0; i < len(columns); i++ {
+		index, ok := indexes[columns[i].Key]
+		if !ok {
+			return errors.Wrapf(ErrUnknownColumn, "the query has no column named %s", columns[i].Key)
+		}
+		sources[i] = index
+	}
+
+	if ws.columns == nil {
+		err = ws.DefineColumns(columns)
+		if err != nil {
+			return errors.Wrap(err, "failed to define the columns")
+		}
+	}
+
+	dest := make([]interface{}, len(types))
+	for i := 0; i < len(types); i++ {
+		switch columnEncoding(types[i]) {
+		case sqlInteger:
+			dest[i] = new(sql.NullInt64)
+		case sqlUnsigned:
+			dest[i] = new(nullUnsigned)
+		case sqlFloat:
+			dest[i] = new(sql.NullFloat64)
+		case sqlDecimal:
+			dest[i] = new(nullDecimal)
+		case sqlDate:
+			dest[i] = new(sql.NullTime)
+		case sqlDateText:
+			dest[i] = new(nullDateText)
+		default:
+			dest[i] = new(sql.NullString)
+		}
+	}
+
+	keys := make([]string, len(columns))
+	for i := 0; i < len(columns); i++ {
+		keys[i] = columns[i].Key
+	}
+	values := make([]interface{}, len(columns))
+
+	f := newFeeder(context.Background(), ws, &BatchOptions{Rows: opts.BatchSize})
+	for count := 1; rows.Next(); count++ {
+		err = rows.Scan(dest...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to scan row %d of the query", count)
+		}
+
+		for i := 0; i < len(columns); i++ {
+			values[i] = scannedValue(dest[sources[i]])
+		}
+		err = ws.addValues(keys, values)
+		if err != nil {
+			return err
+		}
+		err = f.added(valuesSize(values))
+		if err != nil {
+			return err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return errors.Wrap(err, "failed to read the rows of the query")
+	}
+
+	return f.flush()
+}
+
+// hasPendingRows indicates whether or not the worksheet, or any of its overflow worksheets, has rows to be committed.
+func (ws *Worksheet) hasPendingRows() bool {
+	for w := ws; w != nil; w = w.next {
+		if len(w.pendingRows) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// columnEncoding picks how to write a query column from the type it's scanned into, or from its database type if the
+// driver doesn't tell the former.
+func columnEncoding(ct *sql.ColumnType) sqlEncoding {
+	switch t := ct.ScanType(); {
+	case t == nil || t.Kind() == reflect.Interface:
+	case t == timeType || t == reflect.TypeOf(sql.NullTime{}):
+		return sqlDate
+	case t == reflect.TypeOf(sql.NullInt64{}) || t == reflect.TypeOf(sql.NullInt32{}):
+		return sqlInteger
+	case t == reflect.TypeOf(sql.NullFloat64{}):
+		return sqlFloat
+	default:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return sqlInteger
+		case reflect.Uint, reflect.Uint64:
+			return sqlUnsigned
+		case reflect.Float32, reflect.Float64:
+			return sqlFloat
+		}
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.String {
+			return sqlText
+		}
+	}
+
+	name := strings.ToUpper(ct.DatabaseTypeName())
+	if i := strings.IndexAny(name, " ("); i >= 0 {
+		name = name[:i]
+	}
+	switch name {
+	case "INT", "INTEGER", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT", "INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL":
+		return sqlInteger
+	case "UNSIGNED":
+		return sqlUnsigned
+	case "REAL", "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "MONEY":
+		return sqlFloat
+	case "DECIMAL", "NUMERIC":
+		return sqlDecimal
+	case "DATE", "DATETIME", "DATETIME2", "TIMESTAMP", "TIMESTAMPTZ":
+		// The driver doesn't scan the column into a time.Time, its values are parsed from text.
+		return sqlDateText
+	}
+	return sqlText
+}
+
+// scannedValue returns the value scanned into one of the destinations of WriteSQLRows, or nil if it's NULL.
+func scannedValue(dest interface{}) interface{} {
+	switch v := dest.(type) {
+	case *sql.NullInt64:
+		if v.Valid {
+			return v.Int64
+		}
+	case *nullUnsigned:
+		if v.Valid {
+			n, err := strconv.ParseUint(v.String, 10, 64)
+			if err != nil {
+				return v.String
+			}
+			return n
+		}
+	case *sql.NullFloat64:
+		if v.Valid {
+			return v.Float64
+		}
+	case *nullDecimal:
+		// Values that aren't plain numbers, such as NaN, are written as text.
+		if v.Valid {
+			if decimalPattern.MatchString(v.String) {
+				return decimalText(v.String)
+			}
+			return v.String
+		}
+	case *sql.NullTime:
+		if v.Valid {
+			return v.Time
+		}
+	case *nullDateText:
+		if v.Valid {
+			for i := 0; i < len(dateLayouts); i++ {
+				t, err := time.Parse(dateLayouts[i], v.String)
+				if err == nil {
+					return t
+				}
+			}
+			return v.String
+		}
+	case *sql.NullString:
+		if v.Valid {
+			return v.String
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package xlsx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDriver serves the same result set for every query, it describes its columns through the optional driver.Rows
+// interfaces database/sql uses to build ColumnTypes.
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeStmt struct{}
+
+type fakeRows struct {
+	index int
+}
+
+var fakeColumns = []struct {
+	name     string
+	dbType   string
+	scanType reflect.Type
+}{
+	{"id", "BIGINT", reflect.TypeOf(int64(0))},
+	{"name", "VARCHAR", reflect.TypeOf("")},
+	{"total", "DECIMAL", reflect.TypeOf([]byte(nil))},
+	{"created_at", "TIMESTAMP", reflect.TypeOf(time.Time{})},
+	{"units", "UNSIGNED BIGINT", reflect.TypeOf(uint64(0))},
+	{"shipped_on", "DATE", reflect.TypeOf([]byte(nil))},
+}
+
+var fakeData = [][]driver.Value{
+	{int64(1), "Ann <admin>", []byte("10.50"), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), uint64(9007199254740992), []byte("2024-03-05")},
+	{int64(2), nil, []byte("7"), nil, uint64(18446744073709551615), []byte("0000-00-00")},
+	{int64(3), "Bob", []byte("NaN"), time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), nil, nil},
+}
+
+func init() {
+	sql.Register("xlsxfake", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (fakeStmt) Close() error                                    { return nil }
+func (fakeStmt) NumInput() int                                   { return 0 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+func (r *fakeRows) Columns() []string {
+	names := make([]string, len(fakeColumns))
+	for i := 0; i < len(fakeColumns); i++ {
+		names[i] = fakeColumns[i].name
+	}
+	return names
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index >= len(fakeData) {
+		return io.EOF
+	}
+	copy(dest, fakeData[r.index])
+	r.index++
+	return nil
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string { return fakeColumns[index].dbType }
+func (r *fakeRows) ColumnTypeScanType(index int) reflect.Type   { return fakeColumns[index].scanType }
+
+func Test_Worksheet_WriteSQLRows_ShouldWriteQueryResults(t *testing.T) {
+	db, err := sql.Open("xlsxfake", "")
+	if err != nil {
+		t.Errorf("failed to open the database: %v", err)
+		return
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name, total, created_at, units, shipped_on FROM orders")
+	if err != nil {
+		t.Errorf("failed to query: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Orders"})
+	err = ws.WriteSQLRows(rows, &SQLRowsOptions{
+		Columns: []*WorksheetColumn{
+			&WorksheetColumn{Key: "created_at", Value: "Created"},
+			&WorksheetColumn{Key: "total", Value: "Total"},
+			&WorksheetColumn{Key: "name", Value: "Customer"},
+			&WorksheetColumn{Key: "units", Value: "Units"},
+			&WorksheetColumn{Key: "shipped_on", Value: "Shipped"},
+		},
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Errorf("failed to write the rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	expected := `<sheetData>` +
+		`<row r="1"><c r="A1" t="str"><v>Created</v></c><c r="B1" t="str"><v>Total</v></c><c r="C1" t="str"><v>Customer</v></c><c r="D1" t="str"><v>Units</v></c><c r="E1" t="str"><v>Shipped</v></c></row>` +
+		`<row r="2"><c r="A2" s="1" t="n"><v>45352.000000</v></c><c r="B2" s="0" t="n"><v>10.50</v></c><c r="C2" t="str"><v>Ann &lt;admin&gt;</v></c><c r="D2" s="0" t="n"><v>9007199254740992</v></c><c r="E2" s="1" t="n"><v>45356.000000</v></c></row>` +
+		`<row r="3"><c r="B3" s="0" t="n"><v>7</v></c><c r="D3" t="str"><v>18446744073709551615</v></c><c r="E3" t="str"><v>0000-00-00</v></c></row>` +
+		`<row r="4"><c r="A4" s="1" t="n"><v>45353.000000</v></c><c r="B4" t="str"><v>NaN</v></c><c r="C4" t="str"><v>Bob</v></c></row>` +
+		`</sheetData>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected sheet data, found: %s", content)
+	}
+}
+
+func Test_Worksheet_WriteSQLRows_ShouldFail_WhenColumnIsNotInTheQuery(t *testing.T) {
+	db, _ := sql.Open("xlsxfake", "")
+	defer db.Close()
+	rows, _ := db.Query("SELECT * FROM orders")
+	defer rows.Close()
+
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Orders"})
+	err := ws.WriteSQLRows(rows, &SQLRowsOptions{
+		Columns: []*WorksheetColumn{&WorksheetColumn{Key: "email"}},
+	})
+	if err == nil {
+		t.Error("expected an error when selecting a column missing from the query")
+	}
+}
@@ -0,0 +1,255 @@
+package xlsx
+
+import (
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DifferentialFormat is a set of formatting changes applied on top of a cell's own style, such as the formatting
+// of a conditional formatting rule. Colors are RGB or ARGB hex strings, like 9C0006 or FF9C0006.
+type DifferentialFormat struct {
+	FontColor string
+	Bold      bool
+	Italic    bool
+	FillColor string
+}
+
+// Style is the formatting of a cell.
+type Style struct {
+	// NumberFormat is a number format code, such as 0.00 or yyyy-mm-dd. Dates use m/d/yyyy when it's empty.
+	NumberFormat string
+	// Protection controls what a protected worksheet allows users to do with the cell. Cells are locked when it's nil.
+	Protection *CellProtection
+}
+
+// CellProtection has the protection flags of a cell, only enforced when the worksheet is protected.
+type CellProtection struct {
+	// Locked prevents the cell from being edited.
+	Locked bool
+	// Hidden hides the cell formula in the formula bar.
+	Hidden bool
+}
+
+// The cell formats every styles part starts with.
+const (
+	defaultStyle = 0
+	dateStyle    = 1
+)
+
+const dateNumFmtID = 14
+
+// customNumFmtID is the id of the first number format added to the styles part, the ones before it are built in.
+const customNumFmtID = 164
+
+type cellXf struct {
+	numFmtID      int
+	hasProtection bool
+	protection    CellProtection
+}
+
+// styleSheet holds the formats used by the cells of every worksheet. It's guarded by its own lock, as worksheets
+// written concurrently add the formats of their cells as they are created.
+type styleSheet struct {
+	mu           sync.RWMutex
+	cellXfs      []cellXf
+	cellXfsIndex map[cellXf]int
+	numFmts      []string
+	numFmtsIndex map[string]int
+	dxfs         []*DifferentialFormat
+}
+
+func newStyleSheet() *styleSheet {
+	s := &styleSheet{
+		cellXfsIndex: make(map[cellXf]int),
+		numFmtsIndex: make(map[string]int),
+	}
+	s.addCellXf(cellXf{})
+	s.addCellXf(cellXf{numFmtID: dateNumFmtID})
+	return s
+}
+
+func (s *styleSheet) addCellXf(xf cellXf) int {
+	s.mu.RLock()
+	index, ok := s.cellXfsIndex[xf]
+	s.mu.RUnlock()
+	if ok {
+		return index
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, ok = s.cellXfsIndex[xf]
+	if !ok {
+		index = len(s.cellXfs)
+		s.cellXfs = append(s.cellXfs, xf)
+		s.cellXfsIndex[xf] = index
+	}
+	return index
+}
+
+// cellStyle returns the index of the cell format combining a number format with a style.
+func (s *styleSheet) cellStyle(numFmtID int, style *Style) int {
+	xf := cellXf{numFmtID: numFmtID}
+	if style != nil && style.NumberFormat != "" {
+		xf.numFmtID = s.addNumFmt(style.NumberFormat)
+	}
+	if style != nil && style.Protection != nil {
+		xf.hasProtection = true
+		xf.protection = *style.Protection
+	}
+	return s.addCellXf(xf)
+}
+
+// addNumFmt adds a number format to the styles part and returns its id.
+func (s *styleSheet) addNumFmt(code string) int {
+	s.mu.RLock()
+	id, ok := s.numFmtsIndex[code]
+	s.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok = s.numFmtsIndex[code]
+	if !ok {
+		id = customNumFmtID + len(s.numFmts)
+		s.numFmts = append(s.numFmts, code)
+		s.numFmtsIndex[code] = id
+	}
+	return id
+}
+
+// addDifferentialFormat adds a differential format to the styles part and returns its dxfId. Identical formats share
+// the same dxfId.
+func (s *styleSheet) addDifferentialFormat(format *DifferentialFormat) (int, error) {
+	fontColor, err := normalizeColor(format.FontColor)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid font color")
+	}
+	fillColor, err := normalizeColor(format.FillColor)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid fill color")
+	}
+
+	dxf := &DifferentialFormat{
+		FontColor: fontColor,
+		Bold:      format.Bold,
+		Italic:    format.Italic,
+		FillColor: fillColor,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < len(s.dxfs); i++ {
+		if *s.dxfs[i] == *dxf {
+			return i, nil
+		}
+	}
+	s.dxfs = append(s.dxfs, dxf)
+
+	return len(s.dxfs) - 1, nil
+}
+
+// normalizeColor turns a RGB or ARGB hex color into the ARGB form used by SpreadsheetML.
+func normalizeColor(color string) (string, error) {
+	color = strings.ToUpper(strings.TrimPrefix(color, "#"))
+	if color == "" {
+		return "", nil
+	}
+	if len(color) != 6 && len(color) != 8 {
+		return "", errors.Errorf("%s is not a RGB or ARGB hex color", color)
+	}
+	for _, c := range color {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'F') {
+			return "", errors.Errorf("%s is not a RGB or ARGB hex color", color)
+		}
+	}
+	if len(color) == 6 {
+		color = "FF" + color
+	}
+	return color, nil
+}
+
+func (wb *Workbook) createStyles() error {
+	filePath := path.Join(wb.tempDirs.xl, "styles.xml")
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(startStyles)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_STYLES to %s", filePath)
+	}
+
+	if len(wb.styles.numFmts) > 0 {
+		_, err = f.WriteString(startNumFmtsFormat(len(wb.styles.numFmts)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append START_NUM_FMTS to %s", filePath)
+		}
+		for i := 0; i < len(wb.styles.numFmts); i++ {
+			_, err = f.WriteString(numFmtFormat(customNumFmtID+i, wb.styles.numFmts[i]))
+			if err != nil {
+				return errors.Wrapf(err, "failed to append a number format to %s", filePath)
+			}
+		}
+		_, err = f.WriteString(endNumFmts)
+		if err != nil {
+			return errors.Wrapf(err, "failed to append END_NUM_FMTS to %s", filePath)
+		}
+	}
+
+	_, err = f.WriteString(baseStyles)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the base styles to %s", filePath)
+	}
+
+	_, err = f.WriteString(startCellXfsFormat(len(wb.styles.cellXfs)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_CELL_XFS to %s", filePath)
+	}
+	for i := 0; i < len(wb.styles.cellXfs); i++ {
+		xf := wb.styles.cellXfs[i]
+		var protection *CellProtection
+		if xf.hasProtection {
+			protection = &xf.protection
+		}
+		_, err = f.WriteString(xfFormat(xf.numFmtID, protection))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append a cell format to %s", filePath)
+		}
+	}
+	_, err = f.WriteString(endCellXfs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_CELL_XFS to %s", filePath)
+	}
+
+	_, err = f.WriteString(startDxfsFormat(len(wb.styles.dxfs)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_DXFS to %s", filePath)
+	}
+	for i := 0; i < len(wb.styles.dxfs); i++ {
+		d := wb.styles.dxfs[i]
+		_, err = f.WriteString(dxfFormat(d.FontColor, d.Bold, d.Italic, d.FillColor))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append a differential format to %s", filePath)
+		}
+	}
+	_, err = f.WriteString(endDxfs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_DXFS to %s", filePath)
+	}
+
+	_, err = f.WriteString(endStyles)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_STYLES to %s", filePath)
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package xlsx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TableOptions has options used when formatting a worksheet as an Excel table.
+type TableOptions struct {
+	// Name is used in structured references such as Orders[Amount]. Defaults to TableN.
+	Name string
+	// Style is the table style, such as TableStyleMedium2, which is the default.
+	Style string
+	// ShowTotalsRow adds a totals row after the last committed row.
+	ShowTotalsRow bool
+}
+
+type table struct {
+	id        int
+	name      string
+	style     string
+	fileName  string
+	ref       string
+	filterRef string
+	totalsRow bool
+}
+
+const totalsRowLabel = "Total"
+
+// prepareTable validates the table options and writes the rows the table needs before the sheet data is closed. The
+// rows are added to the worksheet itself, even if it overflowed, as they belong to its own table.
+func (ws *Worksheet) prepareTable(ctx context.Context) error {
+	opts := ws.options.AsTable
+
+	if len(ws.columns) == 0 {
+		return errors.Wrap(ErrNoColumns, "a table requires columns defined with DefineColumns")
+	}
+
+	names := make(map[string]bool, len(ws.columns))
+	for i := 0; i < len(ws.columns); i++ {
+		name := strings.ToLower(ws.columns[i].Value)
+		if name == "" {
+			return errors.Errorf("the column %s needs a header value to be used as a table column name", ws.columns[i].Key)
+		}
+		if names[name] {
+			return errors.Errorf("the header value %s is used by more than one column", ws.columns[i].Value)
+		}
+		names[name] = true
+	}
+
+	t, err := ws.workbook.addTable(ws, opts)
+	if err != nil {
+		return err
+	}
+
+	// A table needs at least one data row, even if it is empty.
+	if ws.rowsCount == ws.headerRows {
+		_, err = ws.addRow(ws.rowsCount, &RowOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to add an empty row to the table")
+		}
+		err = ws.commitRows(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to commit an empty row to the table")
+		}
+	}
+	t.filterRef = ws.columnsRange().ref
+
+	if t.totalsRow {
+		row, err := ws.addRow(ws.rowsCount, &RowOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to add the totals row")
+		}
+		cell, err := row.AddCellWithKey(ws.columns[0].Key)
+		if err != nil {
+			return errors.Wrap(err, "failed to add the totals row label")
+		}
+		cell.Value = totalsRowLabel
+		err = ws.commitRows(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to commit the totals row")
+		}
+		ws.footerRows = 1
+	}
+	t.ref = ws.columnsRange().ref
+
+	return nil
+}
+
+// addTable picks the id of the table of the worksheet and reserves its name, the ranges are set once its rows are
+// written.
+func (wb *Workbook) addTable(ws *Worksheet, opts *TableOptions) (*table, error) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	t := &table{
+		id:        wb.tablesCount + 1,
+		name:      opts.Name,
+		style:     opts.Style,
+		totalsRow: opts.ShowTotalsRow,
+	}
+	t.fileName = fmt.Sprintf("table%d.xml", t.id)
+	if t.name == "" {
+		t.name = fmt.Sprintf("Table%d", t.id)
+	}
+	if t.style == "" {
+		t.style = "TableStyleMedium2"
+	}
+
+	err := validateName(t.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid table name")
+	}
+	for i := 0; i < len(wb.worksheets); i++ {
+		other := wb.worksheets[i].table
+		if other != nil && strings.EqualFold(other.name, t.name) {
+			return nil, errors.Wrapf(ErrInvalidName, "the table name %s is already used by worksheet %s", t.name, wb.worksheets[i].name)
+		}
+	}
+
+	wb.tablesCount = t.id
+	ws.table = t
+
+	return t, nil
+}
+
+func (ws *Worksheet) writeTableParts(f *os.File) error {
+	if ws.table == nil {
+		return nil
+	}
+
+	err := ws.createTable()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the table file")
+	}
+
+	relID := ws.addRelationship("http://schemas.openxmlformats.org/officeDocument/2006/relationships/table", path.Join("..", "tables", ws.table.fileName))
+
+	_, err = f.WriteString(startTablePartsFormat(1))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_TABLE_PARTS to file %s", ws.filePath)
+	}
+	_, err = f.WriteString(tablePartFormat(relID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append a table part to file %s", ws.filePath)
+	}
+	_, err = f.WriteString(endTableParts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_TABLE_PARTS to file %s", ws.filePath)
+	}
+
+	return nil
+}
+
+func (ws *Worksheet) createTable() error {
+	t := ws.table
+
+	err := os.MkdirAll(ws.workbook.tempDirs.xlTables, os.ModeDir|os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary directory \"%s\"", ws.workbook.tempDirs.xlTables)
+	}
+
+	filePath := path.Join(ws.workbook.tempDirs.xlTables, t.fileName)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(startTableFormat(t.id, t.name, t.ref, t.filterRef, t.totalsRow))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_TABLE to %s", filePath)
+	}
+
+	_, err = f.WriteString(startTableColumnsFormat(len(ws.columns)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_TABLE_COLUMNS to %s", filePath)
+	}
+	for i := 0; i < len(ws.columns); i++ {
+		label := ""
+		if i == 0 && t.totalsRow {
+			label = totalsRowLabel
+		}
+		_, err = f.WriteString(tableColumnFormat(i+1, ws.columns[i].Value, label))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the table column %s to %s", ws.columns[i].Key, filePath)
+		}
+	}
+	_, err = f.WriteString(endTableColumns)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_TABLE_COLUMNS to %s", filePath)
+	}
+
+	_, err = f.WriteString(tableStyleInfoFormat(t.style))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the table style to %s", filePath)
+	}
+
+	_, err = f.WriteString(endTable)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_TABLE to %s", filePath)
+	}
+
+	return nil
+}
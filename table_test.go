@@ -0,0 +1,144 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+)
+
+func Test_Worksheet_Commit_ShouldCreateTable_WhenAsTableIsSet(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-4.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name: "Orders",
+		AsTable: &TableOptions{
+			Name:          "Orders",
+			ShowTotalsRow: true,
+		},
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "Order ID"},
+		&WorksheetColumn{Key: "amount", Value: "Amount"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	for i := 0; i < 3; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = i
+		cell, _ = row.AddCellWithKey("amount")
+		cell.Value = 1.5
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(path.Join(wb.tempDirs.xlTables, "table1.xml"))
+	if err != nil {
+		t.Errorf("failed to read the table file: %v", err)
+		return
+	}
+	expected := `id="1" name="Orders" displayName="Orders" ref="A1:B5" totalsRowCount="1"><autoFilter ref="A1:B4"/><tableColumns count="2"><tableColumn id="1" name="Order ID" totalsRowLabel="Total"/><tableColumn id="2" name="Amount"/></tableColumns>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected table file content, found: %s", content)
+	}
+
+	content, err = ioutil.ReadFile(path.Join(wb.tempDirs.xlWorksheetsRels, "sheet1.xml.rels"))
+	if err != nil {
+		t.Errorf("failed to read the worksheet relationships file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `Target="../tables/table1.xml"`) {
+		t.Errorf("worksheet relationships are missing the table, found: %s", content)
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
+
+func Test_Worksheet_Commit_ShouldFail_WhenTableColumnNamesRepeat(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:    "Orders",
+		AsTable: &TableOptions{},
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "a", Value: "Amount"},
+		&WorksheetColumn{Key: "b", Value: "amount"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	err = ws.Commit()
+	if err == nil {
+		t.Error("expected an error when committing a table with repeated column names")
+	}
+}
+
+func Test_Worksheet_Commit_ShouldKeepTotalsRowOnEachTable_WhenTableOverflows(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:     "Data",
+		Overflow: true,
+		AsTable:  &TableOptions{ShowTotalsRow: true},
+	})
+	ws.rowsLimit = 5
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+
+	for i := 0; i < 8; i++ {
+		row, err := ws.AddRow()
+		if err != nil {
+			t.Errorf("failed to add row %d: %v", i, err)
+			return
+		}
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = i
+	}
+	err := ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	if len(wb.worksheets) != 3 {
+		t.Errorf("expected 3 worksheets, found %d", len(wb.worksheets))
+		return
+	}
+	for i, ref := range []string{"A1:A5", "A1:A5", "A1:A4"} {
+		ws := wb.worksheets[i]
+		if ws.table.ref != ref {
+			t.Errorf("unexpected table ref on %s, expected %s, found %s", ws.name, ref, ws.table.ref)
+		}
+		content, err := ioutil.ReadFile(ws.filePath)
+		if err != nil {
+			t.Errorf("failed to read the worksheet file: %v", err)
+			return
+		}
+		if strings.Count(string(content), totalsRowLabel) != 1 {
+			t.Errorf("expected a single totals row on %s, found: %s", ws.name, content)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package xlsx
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -18,50 +19,376 @@ func timeToExcelTime(t time.Time) float64 {
 const (
 	startContentTypes  = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`
 	endContentTypes    = "</Types>"
-	rels               = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
-	startWorkbook      = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><fileVersion appName="xl" lastEdited="5" lowestEdited="5" rupBuild="9303"/><workbookPr defaultThemeVersion="124226"/><bookViews><workbookView xWindow="480" yWindow="60" windowWidth="18195" windowHeight="8505"/></bookViews><sheets>`
-	endWorkbook        = `</sheets><calcPr calcId="145621"/></workbook>`
+	startRootRels      = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	endRootRels        = "</Relationships>"
+	startCoreProps     = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:dcmitype="http://purl.org/dc/dcmitype/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">`
+	endCoreProps       = "</cp:coreProperties>"
+	startCustomProps   = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">`
+	endCustomProps     = "</Properties>"
+	startWorkbook      = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><fileVersion appName="xl" lastEdited="5" lowestEdited="5" rupBuild="9303"/><workbookPr defaultThemeVersion="124226"/>`
+	startSheets        = "<sheets>"
+	endSheets          = "</sheets>"
+	startDefinedNames  = "<definedNames>"
+	endDefinedNames    = "</definedNames>"
+	endWorkbook        = `<calcPr calcId="145621"/></workbook>`
 	startWorkbookRels  = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
 	endWorkbookRels    = "</Relationships>"
-	styles             = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="x14ac" xmlns:x14ac="http://schemas.microsoft.com/office/spreadsheetml/2009/9/ac"><fonts count="1" x14ac:knownFonts="1"><font><sz val="11"/><color theme="1"/><name val="Calibri"/><family val="2"/><scheme val="minor"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles><dxfs count="0"/><tableStyles count="0" defaultTableStyle="TableStyleMedium2" defaultPivotStyle="PivotStyleLight16"/><extLst><ext uri="{EB79DEF2-80B8-43e5-95BD-54CBDDF9020C}" xmlns:x14="http://schemas.microsoft.com/office/spreadsheetml/2009/9/main"><x14:slicerStyles defaultSlicerStyle="SlicerStyleLight1"/></ext></extLst></styleSheet>`
-	startWorksheet     = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="x14ac" xmlns:x14ac="http://schemas.microsoft.com/office/spreadsheetml/2009/9/ac"><sheetViews><sheetView workbookViewId="0"/></sheetViews><sheetFormatPr defaultRowHeight="15" x14ac:dyDescent="0.25"/>`
+	startWorksheetRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	endWorksheetRels   = "</Relationships>"
+	startStyles        = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="x14ac" xmlns:x14ac="http://schemas.microsoft.com/office/spreadsheetml/2009/9/ac">`
+	endNumFmts         = "</numFmts>"
+	baseStyles         = `<fonts count="1" x14ac:knownFonts="1"><font><sz val="11"/><color theme="1"/><name val="Calibri"/><family val="2"/><scheme val="minor"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`
+	endCellXfs         = `</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`
+	endDxfs            = "</dxfs>"
+	endStyles          = `<tableStyles count="0" defaultTableStyle="TableStyleMedium2" defaultPivotStyle="PivotStyleLight16"/><extLst><ext uri="{EB79DEF2-80B8-43e5-95BD-54CBDDF9020C}" xmlns:x14="http://schemas.microsoft.com/office/spreadsheetml/2009/9/main"><x14:slicerStyles defaultSlicerStyle="SlicerStyleLight1"/></ext></extLst></styleSheet>`
+	startWorksheet     = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="x14ac" xmlns:x14ac="http://schemas.microsoft.com/office/spreadsheetml/2009/9/ac">`
 	startColumns       = "<cols>"
 	endColumns         = "</cols>"
 	startWorksheetData = "<sheetData>"
 	endRow             = "</row>"
 	endWorksheetData   = "</sheetData>"
+	endMergeCells      = "</mergeCells>"
+	endConditionalFmt  = "</conditionalFormatting>"
+	endDataValidations = "</dataValidations>"
+	endTableColumns    = "</tableColumns>"
+	endTable           = "</table>"
+	endTableParts      = "</tableParts>"
 	endWorksheet       = "</worksheet>"
 )
 
 func overrideWorksheetFormat(fileName string) string {
-	return fmt.Sprintf(`<Override PartName="/xl/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`, fileName)
+	return fmt.Sprintf(`<Override PartName="/xl/worksheets/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, fileName)
 }
 
-func sheetFormat(name string, id int) string {
-	return fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, id, id)
+func overrideFormat(partName, contentType string) string {
+	return fmt.Sprintf(`<Override PartName="%s" ContentType="%s"/>`, partName, contentType)
+}
+
+func corePropFormat(element, value string) string {
+	return fmt.Sprintf(`<%s>%s</%s>`, element, escapeXML(value), element)
+}
+
+func corePropDateFormat(element string, value time.Time) string {
+	return fmt.Sprintf(`<%s xsi:type="dcterms:W3CDTF">%s</%s>`, element, value.UTC().Format(time.RFC3339), element)
+}
+
+func appPropsFormat(application string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"><Application>%s</Application></Properties>`, escapeXML(application))
+}
+
+func customPropFormat(pid int, name, valueType, value string) string {
+	return fmt.Sprintf(`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="%d" name="%s"><vt:%s>%s</vt:%s></property>`, pid, escapeXML(name), valueType, escapeXML(value), valueType)
+}
+
+func overrideTableFormat(fileName string) string {
+	return fmt.Sprintf(`<Override PartName="/xl/tables/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.table+xml"/>`, fileName)
+}
+
+func overrideStylesFormat(fileName string) string {
+	return fmt.Sprintf(`<Override PartName="/xl/%s" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`, fileName)
+}
+
+func workbookProtectionFormat(attrs string) string {
+	return fmt.Sprintf(`<workbookProtection%s/>`, attrs)
+}
+
+func bookViewsFormat(activeTab int) string {
+	if activeTab == 0 {
+		return `<bookViews><workbookView xWindow="480" yWindow="60" windowWidth="18195" windowHeight="8505"/></bookViews>`
+	}
+	return fmt.Sprintf(`<bookViews><workbookView xWindow="480" yWindow="60" windowWidth="18195" windowHeight="8505" activeTab="%d"/></bookViews>`, activeTab)
+}
+
+func sheetFormat(name string, id int, state string) string {
+	if state != "" && state != "visible" {
+		return fmt.Sprintf(`<sheet name="%s" sheetId="%d" state="%s" r:id="rId%d"/>`, escapeXML(name), id, state, id)
+	}
+	return fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(name), id, id)
+}
+
+func sheetPrFormat(content string) string {
+	if content == "" {
+		return ""
+	}
+	return "<sheetPr>" + content + "</sheetPr>"
+}
+
+func tabColorFormat(rgb string) string {
+	return fmt.Sprintf(`<tabColor rgb="%s"/>`, rgb)
+}
+
+func pageSetUpPrFormat(fitToPage bool) string {
+	return fmt.Sprintf(`<pageSetUpPr fitToPage="%d"/>`, boolToInt(fitToPage))
+}
+
+func outlinePrFormat(summaryBelow bool) string {
+	if summaryBelow {
+		return `<outlinePr summaryBelow="1"/>`
+	}
+	return `<outlinePr summaryBelow="0"/>`
+}
+
+func sheetFormatPrFormat(outlineLevelRow, outlineLevelCol int) string {
+	var attrs string
+	if outlineLevelRow > 0 {
+		attrs += fmt.Sprintf(` outlineLevelRow="%d"`, outlineLevelRow)
+	}
+	if outlineLevelCol > 0 {
+		attrs += fmt.Sprintf(` outlineLevelCol="%d"`, outlineLevelCol)
+	}
+	return fmt.Sprintf(`<sheetFormatPr defaultRowHeight="15"%s x14ac:dyDescent="0.25"/>`, attrs)
+}
+
+// tabSelectedOffset is the offset of the tabSelected value within sheetViewsFormat, it's written as 0 and set to 1 on
+// the active worksheet once it's known, when the workbook is committed.
+const tabSelectedOffset = len(`<sheetViews><sheetView tabSelected="`)
+
+func sheetViewsFormat(rightToLeft bool, pane string) string {
+	attrs := ` tabSelected="0"`
+	if rightToLeft {
+		attrs += ` rightToLeft="1"`
+	}
+	if pane == "" {
+		return fmt.Sprintf(`<sheetViews><sheetView%s workbookViewId="0"/></sheetViews>`, attrs)
+	}
+	return fmt.Sprintf(`<sheetViews><sheetView%s workbookViewId="0">%s</sheetView></sheetViews>`, attrs, pane)
+}
+
+func frozenPaneFormat(rows, columns int) string {
+	topLeftCell := createIdentifierFromCoords(columns, rows)
+	switch {
+	case rows > 0 && columns > 0:
+		return fmt.Sprintf(`<pane xSplit="%d" ySplit="%d" topLeftCell="%s" activePane="bottomRight" state="frozen"/><selection pane="topRight"/><selection pane="bottomLeft"/><selection pane="bottomRight"/>`, columns, rows, topLeftCell)
+	case rows > 0:
+		return fmt.Sprintf(`<pane ySplit="%d" topLeftCell="%s" activePane="bottomLeft" state="frozen"/><selection pane="bottomLeft"/>`, rows, topLeftCell)
+	case columns > 0:
+		return fmt.Sprintf(`<pane xSplit="%d" topLeftCell="%s" activePane="topRight" state="frozen"/><selection pane="topRight"/>`, columns, topLeftCell)
+	default:
+		return ""
+	}
+}
+
+func definedNameFormat(name, refersTo string, localSheetID int, hidden bool) string {
+	var attrs string
+	if localSheetID >= 0 {
+		attrs += fmt.Sprintf(` localSheetId="%d"`, localSheetID)
+	}
+	if hidden {
+		attrs += ` hidden="1"`
+	}
+	return fmt.Sprintf(`<definedName name="%s"%s>%s</definedName>`, escapeXML(name), attrs, escapeXML(refersTo))
 }
 
 func relationshipFormat(id int, relType, target string) string {
 	return fmt.Sprintf(`<Relationship Id="rId%d" Type="%s" Target="%s"/>`, id, relType, target)
 }
 
-func columnFormat(width, index int) string {
-	return fmt.Sprintf(`<col min="%d" max="%d" width="%d" customWidth="1"/>`, index, index, width)
+func columnFormat(index, style int, width float64, outlineLevel int, hidden, collapsed bool) string {
+	attrs := outlineAttrs(outlineLevel, hidden, collapsed)
+	if style != defaultStyle {
+		attrs = fmt.Sprintf(` style="%d"`, style) + attrs
+	}
+	if width > 0 {
+		return fmt.Sprintf(`<col min="%d" max="%d" width="%s" customWidth="1"%s/>`, index, index, strconv.FormatFloat(width, 'f', -1, 64), attrs)
+	}
+	return fmt.Sprintf(`<col min="%d" max="%d" width="9.140625"%s/>`, index, index, attrs)
+}
+
+func sheetProtectionFormat(attrs string) string {
+	return fmt.Sprintf(`<sheetProtection%s/>`, attrs)
+}
+
+func autoFilterFormat(ref string) string {
+	return fmt.Sprintf(`<autoFilter ref="%s"/>`, ref)
+}
+
+func startMergeCellsFormat(count int) string {
+	return fmt.Sprintf(`<mergeCells count="%d">`, count)
+}
+
+func mergeCellFormat(ref string) string {
+	return fmt.Sprintf(`<mergeCell ref="%s"/>`, ref)
+}
+
+func startNumFmtsFormat(count int) string {
+	return fmt.Sprintf(`<numFmts count="%d">`, count)
+}
+
+func numFmtFormat(id int, code string) string {
+	return fmt.Sprintf(`<numFmt numFmtId="%d" formatCode="%s"/>`, id, escapeXML(code))
 }
 
-func startRowFormat(index int) string {
-	return fmt.Sprintf(`<row r="%d">`, index)
+func startCellXfsFormat(count int) string {
+	return fmt.Sprintf(`<cellXfs count="%d">`, count)
 }
 
-func cellFormat(identifier, value string) string {
+func xfFormat(numFmtID int, protection *CellProtection) string {
+	var attrs string
+	if numFmtID != 0 {
+		attrs += ` applyNumberFormat="1"`
+	}
+	if protection == nil {
+		return fmt.Sprintf(`<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0"%s/>`, numFmtID, attrs)
+	}
+	return fmt.Sprintf(`<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0"%s applyProtection="1"><protection locked="%d" hidden="%d"/></xf>`, numFmtID, attrs, boolToInt(protection.Locked), boolToInt(protection.Hidden))
+}
+
+func startDxfsFormat(count int) string {
+	return fmt.Sprintf(`<dxfs count="%d">`, count)
+}
+
+func dxfFormat(fontColor string, bold, italic bool, fillColor string) string {
+	var font, fill string
+	if bold {
+		font += "<b/>"
+	}
+	if italic {
+		font += "<i/>"
+	}
+	if fontColor != "" {
+		font += fmt.Sprintf(`<color rgb="%s"/>`, fontColor)
+	}
+	if font != "" {
+		font = "<font>" + font + "</font>"
+	}
+	if fillColor != "" {
+		fill = fmt.Sprintf(`<fill><patternFill><bgColor rgb="%s"/></patternFill></fill>`, fillColor)
+	}
+	return "<dxf>" + font + fill + "</dxf>"
+}
+
+func startConditionalFormattingFormat(sqref string) string {
+	return fmt.Sprintf(`<conditionalFormatting sqref="%s">`, sqref)
+}
+
+func cfRuleFormat(ruleType string, dxfID, priority int, attrs, body string) string {
+	dxf := ""
+	if dxfID >= 0 {
+		dxf = fmt.Sprintf(` dxfId="%d"`, dxfID)
+	}
+	if body == "" {
+		return fmt.Sprintf(`<cfRule type="%s"%s priority="%d"%s/>`, ruleType, dxf, priority, attrs)
+	}
+	return fmt.Sprintf(`<cfRule type="%s"%s priority="%d"%s>%s</cfRule>`, ruleType, dxf, priority, attrs, body)
+}
+
+func formulaFormat(formula string) string {
+	return fmt.Sprintf(`<formula>%s</formula>`, escapeXML(formula))
+}
+
+func cfvoFormat(cfvoType, val string) string {
+	if val == "" {
+		return fmt.Sprintf(`<cfvo type="%s"/>`, cfvoType)
+	}
+	return fmt.Sprintf(`<cfvo type="%s" val="%s"/>`, cfvoType, val)
+}
+
+func colorFormat(rgb string) string {
+	return fmt.Sprintf(`<color rgb="%s"/>`, rgb)
+}
+
+func startDataValidationsFormat(count int) string {
+	return fmt.Sprintf(`<dataValidations count="%d">`, count)
+}
+
+func dataValidationFormat(sqref, attrs, formula1, formula2 string) string {
+	formulas := fmt.Sprintf(`<formula1>%s</formula1>`, escapeXML(formula1))
+	if formula2 != "" {
+		formulas += fmt.Sprintf(`<formula2>%s</formula2>`, escapeXML(formula2))
+	}
+	return fmt.Sprintf(`<dataValidation%s sqref="%s">%s</dataValidation>`, attrs, sqref, formulas)
+}
+
+func printOptionsFormat(gridLines bool) string {
+	return fmt.Sprintf(`<printOptions gridLines="%d"/>`, boolToInt(gridLines))
+}
+
+func pageMarginsFormat(left, right, top, bottom, header, footer float64) string {
+	return fmt.Sprintf(`<pageMargins left="%v" right="%v" top="%v" bottom="%v" header="%v" footer="%v"/>`, left, right, top, bottom, header, footer)
+}
+
+func pageSetupFormat(attrs string) string {
+	return fmt.Sprintf(`<pageSetup%s/>`, attrs)
+}
+
+func headerFooterFormat(header, footer string) string {
+	var content string
+	if header != "" {
+		content += fmt.Sprintf(`<oddHeader>%s</oddHeader>`, escapeXML(header))
+	}
+	if footer != "" {
+		content += fmt.Sprintf(`<oddFooter>%s</oddFooter>`, escapeXML(footer))
+	}
+	return "<headerFooter>" + content + "</headerFooter>"
+}
+
+func startTableFormat(id int, name, ref, autoFilterRef string, totalsRow bool) string {
+	totals := ` totalsRowShown="0"`
+	if totalsRow {
+		totals = ` totalsRowCount="1"`
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><table xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" id="%d" name="%s" displayName="%s" ref="%s"%s><autoFilter ref="%s"/>`, id, name, name, ref, totals, autoFilterRef)
+}
+
+func startTableColumnsFormat(count int) string {
+	return fmt.Sprintf(`<tableColumns count="%d">`, count)
+}
+
+func tableColumnFormat(id int, name, totalsRowLabel string) string {
+	if totalsRowLabel != "" {
+		return fmt.Sprintf(`<tableColumn id="%d" name="%s" totalsRowLabel="%s"/>`, id, escapeXML(name), escapeXML(totalsRowLabel))
+	}
+	return fmt.Sprintf(`<tableColumn id="%d" name="%s"/>`, id, escapeXML(name))
+}
+
+func tableStyleInfoFormat(style string) string {
+	return fmt.Sprintf(`<tableStyleInfo name="%s" showFirstColumn="0" showLastColumn="0" showRowStripes="1" showColumnStripes="0"/>`, escapeXML(style))
+}
+
+func startTablePartsFormat(count int) string {
+	return fmt.Sprintf(`<tableParts count="%d">`, count)
+}
+
+func tablePartFormat(relID int) string {
+	return fmt.Sprintf(`<tablePart r:id="rId%d"/>`, relID)
+}
+
+func startRowFormat(index, outlineLevel int, hidden, collapsed bool) string {
+	attrs := outlineAttrs(outlineLevel, hidden, collapsed)
+	return fmt.Sprintf(`<row r="%d"%s>`, index+1, attrs)
+}
+
+func outlineAttrs(outlineLevel int, hidden, collapsed bool) string {
+	var attrs string
+	if hidden {
+		attrs += ` hidden="1"`
+	}
+	if outlineLevel > 0 {
+		attrs += fmt.Sprintf(` outlineLevel="%d"`, outlineLevel)
+	}
+	if collapsed {
+		attrs += ` collapsed="1"`
+	}
+	return attrs
+}
+
+func cellFormat(identifier, value string, style int) string {
+	value = escapeXML(value)
+	if style != defaultStyle {
+		return fmt.Sprintf(`<c r="%s" s="%d" t="str"><v>%s</v></c>`, identifier, style, value)
+	}
 	return fmt.Sprintf(`<c r="%s" t="str"><v>%s</v></c>`, identifier, value)
 }
 
-func dateCellFormat(identifier string, value time.Time) string {
+func dateCellFormat(identifier string, value time.Time, style int) string {
 	fmtValue := timeToExcelTime(timeToUTCTime(value))
-	return fmt.Sprintf(`<c r="%s" s="1" t="n"><v>%f</v></c>`, identifier, fmtValue)
+	return fmt.Sprintf(`<c r="%s" s="%d" t="n"><v>%f</v></c>`, identifier, style, fmtValue)
+}
+
+func emptyCellFormat(identifier string, style int) string {
+	return fmt.Sprintf(`<c r="%s" s="%d"/>`, identifier, style)
 }
 
-func numberCellFormat(identifier string, value interface{}) string {
-	return fmt.Sprintf(`<c r="%s" s="0" t="n"><v>%v</v></c>`, identifier, value)
+func numberCellFormat(identifier string, value interface{}, style int) string {
+	return fmt.Sprintf(`<c r="%s" s="%d" t="n"><v>%v</v></c>`, identifier, style, value)
 }
@@ -10,9 +10,28 @@ func Test_DateCell_ShouldProperlyCreateCell(t *testing.T) {
 	now := time.Now()
 	expectedCellStr := fmt.Sprintf(`<c r="A1" s="1" t="n"><v>%f</v></c>`, timeToExcelTime(timeToUTCTime(now)))
 
-	cellStr := dateCellFormat("A1", now)
+	cellStr := dateCellFormat("A1", now, dateStyle)
 
 	if cellStr != expectedCellStr {
 		t.Errorf("cell string differs from the expected, found: %s, expected: %s", cellStr, expectedCellStr)
 	}
 }
+
+func Test_FrozenPane_ShouldProperlyCreatePane(t *testing.T) {
+	cases := []struct {
+		rows, columns int
+		expected      string
+	}{
+		{0, 0, ""},
+		{1, 0, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/><selection pane="bottomLeft"/>`},
+		{0, 2, `<pane xSplit="2" topLeftCell="C1" activePane="topRight" state="frozen"/><selection pane="topRight"/>`},
+		{1, 1, `<pane xSplit="1" ySplit="1" topLeftCell="B2" activePane="bottomRight" state="frozen"/><selection pane="topRight"/><selection pane="bottomLeft"/><selection pane="bottomRight"/>`},
+	}
+
+	for _, c := range cases {
+		paneStr := frozenPaneFormat(c.rows, c.columns)
+		if paneStr != c.expected {
+			t.Errorf("pane string differs from the expected, found: %s, expected: %s", paneStr, c.expected)
+		}
+	}
+}
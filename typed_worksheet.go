@@ -0,0 +1,287 @@
+//go:build go1.18
+
+package xlsx
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// TypedWorksheet is a worksheet whose rows are values of T, a struct or a pointer to one, tagged as for WriteStruct.
+// Cells are rendered by accessors built once for T, so rows skip Cell.Value and the reflection done on it.
+type TypedWorksheet[T any] struct {
+	*Worksheet
+	columns []*typedColumn
+}
+
+// typedColumn renders the field of a struct as the cell of a column.
+type typedColumn struct {
+	index     []int
+	letters   string
+	omitEmpty bool
+	// deref is the number of pointers to follow to reach the value, a nil one leaves the cell empty.
+	deref     int
+	style     int
+	dateStyle int
+	write     func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error)
+}
+
+// NewTypedWorksheet adds a worksheet to the workbook with the columns of T.
+func NewTypedWorksheet[T any](wb *Workbook, opts *WorksheetOptions) (*TypedWorksheet[T], error) {
+	t, err := structType(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	fields, err := typeFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := wb.AddWorksheet(opts)
+	if err != nil {
+		return nil, err
+	}
+	err = ws.DefineColumns(fieldsColumns(fields))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to define the columns")
+	}
+
+	tw := &TypedWorksheet[T]{Worksheet: ws}
+	for i := 0; i < len(fields); i++ {
+		c, err := newTypedColumn(t.FieldByIndex(fields[i].index).Type, ws.columns[i].Style, wb.styles)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't write the column %s", fields[i].key)
+		}
+		c.index = fields[i].index
+		c.letters = numericToLetters(i)
+		c.omitEmpty = fields[i].omitEmpty
+		tw.columns = append(tw.columns, c)
+	}
+
+	return tw, nil
+}
+
+func newTypedColumn(t reflect.Type, style *Style, styles *styleSheet) (*typedColumn, error) {
+	c := &typedColumn{
+		style: styles.cellStyle(0, style),
+	}
+	if t.Implements(valuerType) {
+		c.dateStyle = styles.cellStyle(dateNumFmtID, style)
+		c.write = appendValuerCell
+		return c, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		c.deref++
+	}
+	if t.Implements(valuerType) || reflect.PtrTo(t).Implements(valuerType) {
+		c.dateStyle = styles.cellStyle(dateNumFmtID, style)
+		c.write = appendValuerCell
+		return c, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		c.write = func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+			return appendStringCell(b, c, row, v.String())
+		}
+	case reflect.Bool:
+		c.write = func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+			return appendStringCell(b, c, row, strconv.FormatBool(v.Bool()))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.write = func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+			b = appendNumberCellStart(b, c.letters, row, c.style)
+			b = strconv.AppendInt(b, v.Int(), 10)
+			return append(b, "</v></c>"...), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		c.write = func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+			n := v.Uint()
+			if n > maxExactInteger {
+				return appendStringCell(b, c, row, strconv.FormatUint(n, 10))
+			}
+			b = appendNumberCellStart(b, c.letters, row, c.style)
+			b = strconv.AppendUint(b, n, 10)
+			return append(b, "</v></c>"...), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bitSize := t.Bits()
+		c.write = func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+			b = appendNumberCellStart(b, c.letters, row, c.style)
+			b = strconv.AppendFloat(b, v.Float(), 'g', -1, bitSize)
+			return append(b, "</v></c>"...), nil
+		}
+	case reflect.Struct:
+		if t != timeType {
+			return nil, errors.Wrapf(ErrUnsupportedType, "%s is not supported in a cell", t)
+		}
+		c.dateStyle = styles.cellStyle(dateNumFmtID, style)
+		c.write = func(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+			var t time.Time
+			if v.CanAddr() {
+				t = *v.Addr().Interface().(*time.Time)
+			} else {
+				t = v.Interface().(time.Time)
+			}
+			return appendTimeCell(b, c, row, t), nil
+		}
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedType, "%s is not supported in a cell", t.Kind())
+	}
+
+	return c, nil
+}
+
+// Append adds a row with the fields of v. Like rows added with AddRow, it must be committed with CommitRows. No row is
+// added if a field can't be written.
+func (tw *TypedWorksheet[T]) Append(v T) error {
+	rv := reflect.ValueOf(&v).Elem()
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.Wrap(ErrUnsupportedType, "can't write nil")
+		}
+		rv = rv.Elem()
+	}
+
+	row, err := tw.AddRow()
+	if err != nil {
+		return err
+	}
+
+	var rowNumber [20]byte
+	number := strconv.AppendInt(rowNumber[:0], int64(row.index+1), 10)
+	b := make([]byte, 0, 48*len(tw.columns))
+	for i := 0; i < len(tw.columns); i++ {
+		c := tw.columns[i]
+		fv, ok := fieldByIndex(rv, c.index)
+		if !ok || (c.omitEmpty && fv.IsZero()) {
+			continue
+		}
+		for j := 0; j < c.deref && ok; j++ {
+			ok = !fv.IsNil()
+			if ok {
+				fv = fv.Elem()
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		b, err = c.write(b, c, number, fv)
+		if err != nil {
+			row.discard()
+			if limitErr, ok := err.(*LimitError); ok {
+				limitErr.Sheet = row.worksheet.name
+			}
+			return &CellError{
+				Sheet:  row.worksheet.name,
+				Row:    row.index + 1,
+				Column: c.letters,
+				Key:    row.worksheet.columns[i].Key,
+				Err:    err,
+			}
+		}
+	}
+	row.raw = b
+
+	return nil
+}
+
+// AppendAll is just like Append but adds a row for every value. Rows are committed as they are written.
+func (tw *TypedWorksheet[T]) AppendAll(values []T) error {
+	f := newFeeder(context.Background(), tw.Worksheet, nil)
+	for i := 0; i < len(values); i++ {
+		err := tw.Append(values[i])
+		if err != nil {
+			return errors.Wrapf(err, "failed to write element %d", i)
+		}
+		err = f.added(0)
+		if err != nil {
+			return err
+		}
+	}
+	return f.flush()
+}
+
+func appendCellRef(b []byte, letters string, row []byte) []byte {
+	b = append(b, `<c r="`...)
+	b = append(b, letters...)
+	return append(b, row...)
+}
+
+// appendNumberCellStart appends the start of a cell as written by numberCellFormat, up to its value.
+func appendNumberCellStart(b []byte, letters string, row []byte, style int) []byte {
+	b = appendCellRef(b, letters, row)
+	b = append(b, `" s="`...)
+	b = strconv.AppendInt(b, int64(style), 10)
+	return append(b, `" t="n"><v>`...)
+}
+
+// appendStringCell appends a cell as written by cellFormat, empty strings are omitted.
+func appendStringCell(b []byte, c *typedColumn, row []byte, s string) ([]byte, error) {
+	if s == "" {
+		return b, nil
+	}
+	if len(s) > maxCellLength && utf8.RuneCountInString(s) > maxCellLength {
+		return b, &LimitError{Limit: CellLengthLimit, Max: maxCellLength}
+	}
+	b = appendCellRef(b, c.letters, row)
+	if c.style != defaultStyle {
+		b = append(b, `" s="`...)
+		b = strconv.AppendInt(b, int64(c.style), 10)
+	}
+	b = append(b, `" t="str"><v>`...)
+	b = append(b, escapeXML(s)...)
+	return append(b, "</v></c>"...), nil
+}
+
+// appendTimeCell appends a cell as written by dateCellFormat.
+func appendTimeCell(b []byte, c *typedColumn, row []byte, t time.Time) []byte {
+	b = appendNumberCellStart(b, c.letters, row, c.dateStyle)
+	b = strconv.AppendFloat(b, timeToExcelTime(timeToUTCTime(t)), 'f', 6, 64)
+	return append(b, "</v></c>"...)
+}
+
+// appendValuerCell appends the value of a driver.Valuer, it's the slow path as the value is only known at runtime.
+func appendValuerCell(b []byte, c *typedColumn, row []byte, v reflect.Value) ([]byte, error) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return b, nil
+	}
+	valuer, ok := v.Interface().(driver.Valuer)
+	if !ok {
+		valuer = v.Addr().Interface().(driver.Valuer)
+	}
+	value, err := valuer.Value()
+	if err != nil {
+		return b, errors.Wrap(err, "failed to retrieve the Value of a driver.Valuer")
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return b, nil
+	case string:
+		return appendStringCell(b, c, row, value)
+	case []byte:
+		return appendStringCell(b, c, row, string(value))
+	case bool:
+		return appendStringCell(b, c, row, strconv.FormatBool(value))
+	case int64:
+		b = appendNumberCellStart(b, c.letters, row, c.style)
+		b = strconv.AppendInt(b, value, 10)
+		return append(b, "</v></c>"...), nil
+	case float64:
+		b = appendNumberCellStart(b, c.letters, row, c.style)
+		b = strconv.AppendFloat(b, value, 'g', -1, 64)
+		return append(b, "</v></c>"...), nil
+	case time.Time:
+		return appendTimeCell(b, c, row, value), nil
+	}
+	return b, errors.Wrapf(ErrUnsupportedType, "%T is not supported in a cell", value)
+}
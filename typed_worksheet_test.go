@@ -0,0 +1,216 @@
+//go:build go1.18
+
+package xlsx
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type shipment struct {
+	ID        int64          `xlsx:"id,header=ID"`
+	Weight    float32        `xlsx:"weight,format=0.00"`
+	Count     *uint          `xlsx:"count"`
+	Recipient string         `xlsx:"recipient"`
+	Fragile   bool           `xlsx:"fragile"`
+	Carrier   sql.NullString `xlsx:"carrier"`
+	ShippedAt time.Time      `xlsx:"shipped_at"`
+}
+
+func testShipments(n int) []shipment {
+	count := uint(3)
+	shipments := make([]shipment, n)
+	for i := 0; i < n; i++ {
+		shipments[i] = shipment{
+			ID:        int64(i),
+			Weight:    1.25,
+			Count:     &count,
+			Recipient: "Smith & Sons",
+			Fragile:   i%2 == 0,
+			Carrier:   sql.NullString{String: "UPS", Valid: i%3 == 0},
+			ShippedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		}
+	}
+	return shipments
+}
+
+func sheetData(t *testing.T, ws *Worksheet) string {
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Fatalf("failed to read the worksheet file: %v", err)
+	}
+	start := strings.Index(string(content), "<sheetData>")
+	end := strings.Index(string(content), "</sheetData>")
+	return string(content[start:end])
+}
+
+func Test_TypedWorksheet_AppendAll_ShouldWriteTheSameRowsAsWriteStructs(t *testing.T) {
+	shipments := testShipments(5)
+
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	tw, err := NewTypedWorksheet[shipment](wb, &WorksheetOptions{Name: "Typed"})
+	if err != nil {
+		t.Errorf("failed to add the typed worksheet: %v", err)
+		return
+	}
+	err = tw.AppendAll(shipments)
+	if err != nil {
+		t.Errorf("failed to append the rows: %v", err)
+		return
+	}
+	err = tw.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Structs"})
+	err = ws.WriteStructs(shipments)
+	if err != nil {
+		t.Errorf("failed to write the structs: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	typed, structs := sheetData(t, tw.Worksheet), sheetData(t, ws)
+	if typed != structs {
+		t.Errorf("typed rows differ from the struct rows:\n%s\n%s", typed, structs)
+	}
+	if !strings.Contains(typed, `<row r="2"><c r="A2" s="0" t="n"><v>0</v></c><c r="B2" s="2" t="n"><v>1.25</v></c><c r="C2" s="0" t="n"><v>3</v></c><c r="D2" t="str"><v>Smith &amp; Sons</v></c><c r="E2" t="str"><v>true</v></c><c r="F2" t="str"><v>UPS</v></c><c r="G2" s="1" t="n"><v>45352.500000</v></c></row>`) {
+		t.Errorf("unexpected typed rows, found: %s", typed)
+	}
+}
+
+func Test_NewTypedWorksheet_ShouldFail_WhenFieldIsNotSupported(t *testing.T) {
+	type invalid struct {
+		Tags []string
+	}
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	_, err := NewTypedWorksheet[invalid](wb, &WorksheetOptions{})
+	if err == nil {
+		t.Error("expected an error when a field can't be written to a cell")
+	}
+}
+
+func Test_TypedWorksheet_Append_ShouldNotLeaveARow_WhenValueFails(t *testing.T) {
+	type note struct {
+		Text string `xlsx:"text"`
+	}
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	tw, err := NewTypedWorksheet[note](wb, &WorksheetOptions{})
+	if err != nil {
+		t.Errorf("failed to add the typed worksheet: %v", err)
+		return
+	}
+	err = tw.Append(note{Text: strings.Repeat("a", maxCellLength+1)})
+	if err == nil {
+		t.Error("expected an error when the value is too long for a cell")
+	}
+	err = tw.Append(note{Text: "valid"})
+	if err != nil {
+		t.Errorf("failed to append the row: %v", err)
+		return
+	}
+	err = tw.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = tw.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	data := sheetData(t, tw.Worksheet)
+	if !strings.Contains(data, `<row r="2"><c r="A2" t="str"><v>valid</v></c></row>`) || strings.Contains(data, `<row r="3"`) {
+		t.Errorf("expected the valid row right after the header, found: %s", data)
+	}
+}
+
+func Test_TypedWorksheet_Append_ShouldWriteText_WhenUnsignedIsNotExact(t *testing.T) {
+	type counter struct {
+		Exact uint64 `xlsx:"exact"`
+		Large uint64 `xlsx:"large"`
+	}
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	tw, err := NewTypedWorksheet[counter](wb, &WorksheetOptions{})
+	if err != nil {
+		t.Errorf("failed to add the typed worksheet: %v", err)
+		return
+	}
+	err = tw.Append(counter{Exact: 1 << 53, Large: 1<<53 + 1})
+	if err != nil {
+		t.Errorf("failed to append the row: %v", err)
+		return
+	}
+	err = tw.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = tw.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	data := sheetData(t, tw.Worksheet)
+	if !strings.Contains(data, `<row r="2"><c r="A2" s="0" t="n"><v>9007199254740992</v></c><c r="B2" t="str"><v>9007199254740993</v></c></row>`) {
+		t.Errorf("unexpected typed rows, found: %s", data)
+	}
+}
+
+func Benchmark_TypedWorksheet_Append(b *testing.B) {
+	shipments := testShipments(1)
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	tw, _ := NewTypedWorksheet[shipment](wb, &WorksheetOptions{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tw.Append(shipments[0])
+		if len(tw.pendingRows) == defaultBatchRows {
+			tw.CommitRows()
+		}
+	}
+}
+
+func Benchmark_Worksheet_AddCellWithKey(b *testing.B) {
+	shipments := testShipments(1)
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+	columns, _ := StructColumns(shipment{})
+	ws.DefineColumns(columns)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := shipments[0]
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = s.ID
+		cell, _ = row.AddCellWithKey("weight")
+		cell.Value = s.Weight
+		cell, _ = row.AddCellWithKey("count")
+		cell.Value = *s.Count
+		cell, _ = row.AddCellWithKey("recipient")
+		cell.Value = s.Recipient
+		cell, _ = row.AddCellWithKey("fragile")
+		cell.Value = s.Fragile
+		cell, _ = row.AddCellWithKey("carrier")
+		cell.Value = s.Carrier
+		cell, _ = row.AddCellWithKey("shipped_at")
+		cell.Value = s.ShippedAt
+		if len(ws.pendingRows) == defaultBatchRows {
+			ws.CommitRows()
+		}
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 func Test_Usage(t *testing.T) {
 	workbook := NewWorkbook("./spreadsheet-1.xlsx")
-	worksheet := workbook.AddWorksheet(&WorksheetOptions{
+	worksheet, _ := workbook.AddWorksheet(&WorksheetOptions{
 		Name: "Data",
 	})
 
@@ -69,7 +69,7 @@ func Test_Usage(t *testing.T) {
 
 func Test_Usage_AddCellWithKey(t *testing.T) {
 	workbook := NewWorkbook("./spreadsheet-2.xlsx")
-	worksheet := workbook.AddWorksheet(&WorksheetOptions{
+	worksheet, _ := workbook.AddWorksheet(&WorksheetOptions{
 		Name: "Data",
 	})
 
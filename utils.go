@@ -1,6 +1,18 @@
 package xlsx
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var xmlReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func escapeXML(s string) string {
+	return xmlReplacer.Replace(s)
+}
 
 func createIdentifierFromCoords(x, y int) string {
 	letterPart := numericToLetters(x)
@@ -8,6 +20,120 @@ func createIdentifierFromCoords(x, y int) string {
 	return fmt.Sprintf("%s%d", letterPart, numericPart)
 }
 
+// maxNameLength is the maximum number of characters of defined names and table names.
+const maxNameLength = 255
+
+var (
+	namePattern          = regexp.MustCompile(`^[\p{L}_\\][\p{L}\p{N}_.\\]*$`)
+	cellReferencePattern = regexp.MustCompile(`^(?i)([A-Z]{1,3}[0-9]+|R[0-9]*C[0-9]*|[RC])$`)
+)
+
+// validateName checks the syntax shared by defined names and table names.
+func validateName(name string) error {
+	if name == "" {
+		return errors.Wrap(ErrInvalidName, "name can't be empty")
+	}
+	if len([]rune(name)) > maxNameLength {
+		return errors.Wrapf(ErrInvalidName, "name %q is longer than %d characters", name, maxNameLength)
+	}
+	if !namePattern.MatchString(name) {
+		return errors.Wrapf(ErrInvalidName, "name %q must start with a letter, an underscore or a backslash and contain only letters, numbers, periods and underscores", name)
+	}
+	if cellReferencePattern.MatchString(name) {
+		return errors.Wrapf(ErrInvalidName, "name %q can't look like a cell reference", name)
+	}
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func createRangeFromCoords(x1, y1, x2, y2 int) string {
+	return fmt.Sprintf("%s:%s", createIdentifierFromCoords(x1, y1), createIdentifierFromCoords(x2, y2))
+}
+
+func createAbsoluteIdentifierFromCoords(x, y int) string {
+	return fmt.Sprintf("$%s$%d", numericToLetters(x), y+1)
+}
+
+// createAbsoluteRangeFromCoords creates a sheet qualified absolute reference such as 'Data'!$A$1:$C$10.
+func createAbsoluteRangeFromCoords(sheetName string, x1, y1, x2, y2 int) string {
+	return fmt.Sprintf("%s!%s:%s", quoteSheetName(sheetName), createAbsoluteIdentifierFromCoords(x1, y1), createAbsoluteIdentifierFromCoords(x2, y2))
+}
+
+func quoteSheetName(name string) string {
+	return fmt.Sprintf("'%s'", strings.Replace(name, "'", "''", -1))
+}
+
+// parseIdentifier parses a cell identifier such as B3 into its zero based coordinates, the letters may be lowercase.
+func parseIdentifier(identifier string) (x, y int, err error) {
+	ref := strings.ToUpper(identifier)
+	i := 0
+	for i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z' {
+		i++
+	}
+	if i == 0 || i == len(ref) {
+		return 0, 0, errors.Errorf("invalid cell identifier %q", identifier)
+	}
+
+	x, err = lettersToNumeric(ref[:i])
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid cell identifier %q", identifier)
+	}
+
+	y = 0
+	for _, c := range ref[i:] {
+		if c < '0' || c > '9' || y > maxRows {
+			return 0, 0, errors.Errorf("invalid cell identifier %q", identifier)
+		}
+		y = y*10 + int(c-'0')
+	}
+	if y == 0 {
+		return 0, 0, errors.Errorf("invalid cell identifier %q", identifier)
+	}
+
+	return x, y - 1, nil
+}
+
+// parseRange parses a range such as A1:D1 into its zero based coordinates.
+func parseRange(ref string) (x1, y1, x2, y2 int, err error) {
+	parts := strings.Split(ref, ":")
+	if len(parts) != 2 {
+		return 0, 0, 0, 0, errors.Errorf("invalid range %q", ref)
+	}
+	x1, y1, err = parseIdentifier(parts[0])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	x2, y2, err = parseIdentifier(parts[1])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return x1, y1, x2, y2, nil
+}
+
+// lettersToNumeric is the inverse of numericToLetters, AA is 26. The letters may be lowercase.
+func lettersToNumeric(letters string) (int, error) {
+	n := 0
+	for _, c := range strings.ToUpper(letters) {
+		if c < 'A' || c > 'Z' {
+			return 0, errors.Errorf("invalid column letters %q", letters)
+		}
+		n = n*26 + int(c-'A') + 1
+		if n > maxColumns {
+			return 0, errors.Errorf("column %s is beyond the last column", letters)
+		}
+	}
+	if n == 0 {
+		return 0, errors.New("empty column letters")
+	}
+	return n - 1, nil
+}
+
 func numericToLetters(colRef int) string {
 	parts := intToBase26(colRef)
 	return formatColumnName(smooshBase26Slice(parts))
@@ -68,12 +194,12 @@ func formatColumnName(colID []int) string {
 			// range 0-25, all other numbers are 1-26,
 			// hence we use a different offset for the
 			// last part.
-			result += string(part + 65)
+			result += string(rune(part + 65))
 		} else {
 			// Don't output leading 0s, as there is no
 			// representation of 0 in this format.
 			if part > 0 {
-				result += string(part + 64)
+				result += string(rune(part + 64))
 			}
 		}
 	}
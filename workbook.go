@@ -2,30 +2,115 @@ package xlsx
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
-	"github.com/jhoonb/archivex"
 	"github.com/pkg/errors"
 )
 
 // Workbook represents a spreadsheet workbook.
+//
+// Each worksheet can be written from its own goroutine, as the state they share through the workbook, such as the
+// temporary directories, styles, tables and names, is guarded. Strings are written inline, so there is no shared
+// strings table to contend for, and relationships belong to each worksheet until the workbook is committed. A worksheet
+// itself must not be used from more than one goroutine at a time, and Commit must be called once every worksheet is
+// committed. Cancelling the context given to the commit of any worksheet aborts the whole workbook, after which every
+// worksheet fails with ErrAborted.
 type Workbook struct {
+	// mu guards the state shared by the worksheets, the styles have their own lock.
+	mu              sync.Mutex
 	FilePath        string
 	worksheets      []*Worksheet
 	tempDirsCreated bool
 	tempRootDir     string
 	tempDirs        *workbookTempDirs
 	relationships   []*relationship
+	tablesCount     int
+	styles          *styleSheet
+	activeSheet     *Worksheet
+	protection      string
+	names           []*definedName
+	options         WorkbookOptions
 	committed       bool
+	aborted         bool
 }
 
+const maxWorksheetNameLength = 31
+
+// WorkbookOptions has options used when creating a new workbook.
+type WorkbookOptions struct {
+	// Properties are the document properties, such as the title and author, shown by Excel and document management
+	// systems.
+	Properties *DocumentProperties
+	// CompressionLevel is the level the parts of the file are compressed with, from flate.BestSpeed to
+	// flate.BestCompression, or flate.HuffmanOnly. The default level of compress/flate is used when it's zero, use
+	// NoCompression rather than flate.NoCompression.
+	CompressionLevel int
+	// NoCompression stores the parts of the file without compressing them, which is the fastest but gives the largest
+	// files. CompressionLevel is ignored when it's set.
+	NoCompression bool
+	// CompressionWorkers is the number of parts compressed concurrently, GOMAXPROCS by default.
+	CompressionWorkers int
+	// NoOverwrite makes Commit fail with ErrFileExists, rather than replace the file, when it already exists. On file
+	// systems without hard links, the file can then be seen partially written until Commit returns.
+	NoOverwrite bool
+}
+
+// WorksheetState is the visibility of a worksheet.
+type WorksheetState string
+
+// Worksheet states.
+const (
+	WorksheetVisible WorksheetState = "visible"
+	// WorksheetHidden worksheets can be unhidden by users from Excel.
+	WorksheetHidden WorksheetState = "hidden"
+	// WorksheetVeryHidden worksheets can only be unhidden with VBA.
+	WorksheetVeryHidden WorksheetState = "veryHidden"
+)
+
 // WorksheetOptions has options used when creating a new worksheet.
 type WorksheetOptions struct {
 	Name string
+	// State is the worksheet visibility, it's visible by default.
+	State WorksheetState
+	// TabColor is the RGB or ARGB hex color of the worksheet tab.
+	TabColor string
+	// RightToLeft displays the worksheet from right to left.
+	RightToLeft bool
+	// Protection protects the worksheet, cells can be unlocked through their Style.
+	Protection *SheetProtection
+	// PageSetup has the options used when printing the worksheet.
+	PageSetup *PageSetup
+	// NameColumns defines a workbook scoped name, such as Orders_amount, for the data rows of each column declared
+	// with DefineColumns.
+	NameColumns bool
+	// FreezeRows is the number of rows, counting from the top, kept visible while scrolling.
+	FreezeRows int
+	// FreezeColumns is the number of columns, counting from the left, kept visible while scrolling.
+	FreezeColumns int
+	// FreezeHeader freezes the header row written by DefineColumns, or FreezeRows rows if there are more of them.
+	FreezeHeader bool
+	// AutoFilter turns on the filter drop-downs across the header row written by DefineColumns.
+	AutoFilter bool
+	// AsTable formats the header written by DefineColumns and every row after it as an Excel table.
+	AsTable *TableOptions
+	// SummaryRowsAbove places the summary row of an outline group above its details instead of below.
+	SummaryRowsAbove bool
+	// MaxOutlineLevel is the deepest outline level of the rows, from 0 to 7. It's written before the rows, so rows
+	// can't be grouped deeper than it.
+	MaxOutlineLevel int
+	// Overflow continues on a new worksheet, such as "Data (2)", once the rows limit is reached instead of failing.
+	// The header written by DefineColumns is repeated on it. Rows are still added through the first worksheet.
+	Overflow bool
 }
 
 type workbookTempDirs struct {
@@ -33,6 +118,10 @@ type workbookTempDirs struct {
 	xl           string
 	xlRels       string
 	xlWorksheets string
+	docProps     string
+	// xlWorksheetsRels and xlTables are only created when a worksheet needs them.
+	xlWorksheetsRels string
+	xlTables         string
 }
 
 type relationship struct {
@@ -40,20 +129,66 @@ type relationship struct {
 	target  string
 }
 
-// AddWorksheet adds a new worksheet to the workbook.
-func (wb *Workbook) AddWorksheet(opts *WorksheetOptions) *Worksheet {
+// AddWorksheet adds a new worksheet to the workbook. The name must be unique within the workbook, ignoring case, be at
+// most 31 characters long and can't contain any of []:*?/\ or start or end with an apostrophe. When empty, a name
+// such as Sheet1 is generated.
+func (wb *Workbook) AddWorksheet(opts *WorksheetOptions) (*Worksheet, error) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.addWorksheet(opts)
+}
+
+func (wb *Workbook) addWorksheet(opts *WorksheetOptions) (*Worksheet, error) {
+	if wb.committed {
+		return nil, errors.Wrap(ErrCommitted, "can't add worksheets to the workbook")
+	}
+
+	if wb.aborted {
+		return nil, errors.Wrap(ErrAborted, "can't add worksheets to the workbook")
+	}
+
 	id := len(wb.worksheets) + 1
 
+	name := opts.Name
+	if name == "" {
+		for n := id; name == "" || wb.hasWorksheet(name); n++ {
+			name = fmt.Sprintf("Sheet%d", n)
+		}
+	}
+	err := wb.validateWorksheetName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxOutlineLevel < 0 || opts.MaxOutlineLevel > maxOutlineLevel {
+		return nil, errors.Errorf("the max outline level must be within 0 and %d", maxOutlineLevel)
+	}
+
+	switch opts.State {
+	case "", WorksheetVisible, WorksheetHidden, WorksheetVeryHidden:
+	default:
+		return nil, errors.Errorf("unknown worksheet state %q", opts.State)
+	}
+
+	if opts.TabColor != "" {
+		_, err = normalizeColor(opts.TabColor)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid tab color")
+		}
+	}
+
 	var fileName bytes.Buffer
 	fileName.WriteString("sheet")
 	fileName.WriteString(fmt.Sprint(id))
 	fileName.WriteString(".xml")
 
 	ws := &Worksheet{
-		workbook: wb,
-		id:       id,
-		name:     opts.Name,
-		fileName: fileName.String(),
+		workbook:  wb,
+		id:        id,
+		name:      name,
+		fileName:  fileName.String(),
+		options:   *opts,
+		rowsLimit: maxRows,
 	}
 	wb.worksheets = append(wb.worksheets, ws)
 
@@ -62,11 +197,95 @@ func (wb *Workbook) AddWorksheet(opts *WorksheetOptions) *Worksheet {
 		target:  path.Join("worksheets", ws.fileName),
 	})
 
-	return ws
+	return ws, nil
+}
+
+func (wb *Workbook) validateWorksheetName(name string) error {
+	if utf8.RuneCountInString(name) > maxWorksheetNameLength {
+		return errors.Wrapf(ErrInvalidName, "worksheet name %q is longer than %d characters", name, maxWorksheetNameLength)
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) {
+		return errors.Wrapf(ErrInvalidName, `worksheet name %q can't contain any of []:*?/\`, name)
+	}
+	if strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return errors.Wrapf(ErrInvalidName, "worksheet name %q can't start or end with an apostrophe", name)
+	}
+	if strings.EqualFold(name, "History") {
+		return errors.Wrapf(ErrInvalidName, "worksheet name %q is reserved by Excel", name)
+	}
+	if wb.hasWorksheet(name) {
+		return errors.Wrapf(ErrInvalidName, "worksheet name %q is already used", name)
+	}
+	return nil
+}
+
+// hasWorksheet indicates whether or not there's a worksheet with the given name, ignoring case as Excel does.
+func (wb *Workbook) hasWorksheet(name string) bool {
+	for i := 0; i < len(wb.worksheets); i++ {
+		if strings.EqualFold(wb.worksheets[i].name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetActiveSheet sets the worksheet shown when the workbook is opened, by default it's the first visible one. It can be
+// called at any time before the workbook is committed.
+func (wb *Workbook) SetActiveSheet(ws *Worksheet) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if wb.committed {
+		return errors.Wrap(ErrCommitted, "can't change the active worksheet")
+	}
+
+	if ws.workbook != wb {
+		return errors.New("can't activate a worksheet from another workbook")
+	}
+
+	if ws.hidden() {
+		return errors.Errorf("can't activate the %s worksheet %s", ws.options.State, ws.name)
+	}
+
+	wb.activeSheet = ws
+
+	return nil
+}
+
+// selectActiveWorksheet sets the tabSelected value, written as 0 by every worksheet, of the active worksheet.
+func (wb *Workbook) selectActiveWorksheet() error {
+	ws := wb.activeWorksheet()
+	f, err := os.OpenFile(ws.filePath, os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %s to select the worksheet", ws.filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteAt([]byte("1"), ws.tabSelectedOffset)
+	if err != nil {
+		return errors.Wrapf(err, "failed to select the worksheet on file %s", ws.filePath)
+	}
+	return f.Close()
+}
+
+// activeWorksheet is the worksheet selected by SetActiveSheet or the first visible one.
+func (wb *Workbook) activeWorksheet() *Worksheet {
+	if wb.activeSheet != nil {
+		return wb.activeSheet
+	}
+	for i := 0; i < len(wb.worksheets); i++ {
+		if !wb.worksheets[i].hidden() {
+			return wb.worksheets[i]
+		}
+	}
+	return nil
 }
 
 // HasPendingWorksheets indicates whether or not the workbook has worksheets yet to be committed.
 func (wb *Workbook) HasPendingWorksheets() bool {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
 	for i := 0; i < len(wb.worksheets); i++ {
 		if !wb.worksheets[i].committed {
 			return true
@@ -90,12 +309,40 @@ func (wb *Workbook) createContentTypes() error {
 		return errors.Wrapf(err, "failed to append START_CONTENT_TYPES to %s", ctFilePath)
 	}
 
+	_, err = f.WriteString(overrideStylesFormat("styles.xml"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append override for styles.xml to %s", ctFilePath)
+	}
+
+	_, err = f.WriteString(overrideFormat("/docProps/core.xml", "application/vnd.openxmlformats-package.core-properties+xml"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append override for core.xml to %s", ctFilePath)
+	}
+	_, err = f.WriteString(overrideFormat("/docProps/app.xml", "application/vnd.openxmlformats-officedocument.extended-properties+xml"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append override for app.xml to %s", ctFilePath)
+	}
+	if wb.hasCustomProperties() {
+		_, err = f.WriteString(overrideFormat("/docProps/custom.xml", "application/vnd.openxmlformats-officedocument.custom-properties+xml"))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append override for custom.xml to %s", ctFilePath)
+		}
+	}
+
 	for i := 0; i < len(wb.worksheets); i++ {
 		wsFileName := wb.worksheets[i].fileName
 		_, err = f.WriteString(overrideWorksheetFormat(wsFileName))
 		if err != nil {
 			return errors.Wrapf(err, "failed to append override for %s to %s", wsFileName, ctFilePath)
 		}
+
+		t := wb.worksheets[i].table
+		if t != nil {
+			_, err = f.WriteString(overrideTableFormat(t.fileName))
+			if err != nil {
+				return errors.Wrapf(err, "failed to append override for %s to %s", t.fileName, ctFilePath)
+			}
+		}
 	}
 
 	_, err = f.WriteString(endContentTypes)
@@ -114,9 +361,30 @@ func (wb *Workbook) createRootRelationships() error {
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(rels)
+	_, err = f.WriteString(startRootRels)
 	if err != nil {
-		return errors.Wrapf(err, "failed to write to %s", filePath)
+		return errors.Wrapf(err, "failed to append START_ROOT_RELS to %s", filePath)
+	}
+
+	rootRels := []*relationship{
+		&relationship{relType: "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument", target: "xl/workbook.xml"},
+		&relationship{relType: "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties", target: "docProps/core.xml"},
+		&relationship{relType: "http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties", target: "docProps/app.xml"},
+	}
+	if wb.hasCustomProperties() {
+		rootRels = append(rootRels, &relationship{relType: "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties", target: "docProps/custom.xml"})
+	}
+	for i := 0; i < len(rootRels); i++ {
+		r := rootRels[i]
+		_, err = f.WriteString(relationshipFormat(i+1, r.relType, r.target))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the relationship with the target %s to %s", r.target, filePath)
+		}
+	}
+
+	_, err = f.WriteString(endRootRels)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_ROOT_RELS to %s", filePath)
 	}
 
 	return nil
@@ -143,6 +411,12 @@ func (wb *Workbook) createWorkbookRelationships() error {
 		}
 	}
 
+	// The worksheet relationship ids match the sheet ids, so the styles come after them.
+	_, err = f.WriteString(relationshipFormat(len(wb.relationships)+1, "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles", "styles.xml"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the relationship with the target styles.xml to %s", filePath)
+	}
+
 	_, err = f.WriteString(endWorkbookRels)
 	if err != nil {
 		return errors.Wrapf(err, "failed to append END_WORKBOOK_RELS to %s", filePath)
@@ -164,9 +438,60 @@ func (wb *Workbook) createMain() error {
 		return errors.Wrapf(err, "failed to append START_WORKBOOK to %s", filePath)
 	}
 
+	if wb.protection != "" {
+		_, err = f.WriteString(wb.protection)
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the workbook protection to %s", filePath)
+		}
+	}
+
+	activeTab := 0
+	active := wb.activeWorksheet()
+	for i := 0; i < len(wb.worksheets); i++ {
+		if wb.worksheets[i] == active {
+			activeTab = i
+		}
+	}
+	_, err = f.WriteString(bookViewsFormat(activeTab))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the book views to %s", filePath)
+	}
+
+	_, err = f.WriteString(startSheets)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_SHEETS to %s", filePath)
+	}
+
 	for i := 0; i < len(wb.worksheets); i++ {
 		ws := wb.worksheets[i]
-		_, err = f.WriteString(sheetFormat(ws.name, ws.id))
+		_, err = f.WriteString(sheetFormat(ws.name, ws.id, string(ws.options.State)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the sheet %s to %s", ws.name, filePath)
+		}
+	}
+
+	_, err = f.WriteString(endSheets)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_SHEETS to %s", filePath)
+	}
+
+	names := wb.definedNames()
+	if len(names) > 0 {
+		_, err = f.WriteString(startDefinedNames)
+		if err != nil {
+			return errors.Wrapf(err, "failed to append START_DEFINED_NAMES to %s", filePath)
+		}
+		for i := 0; i < len(names); i++ {
+			n := names[i]
+			_, err = f.WriteString(definedNameFormat(n.name, n.refersTo, n.localSheetID, n.hidden))
+			if err != nil {
+				return errors.Wrapf(err, "failed to append the defined name %s to %s", n.name, filePath)
+			}
+		}
+		_, err = f.WriteString(endDefinedNames)
+		if err != nil {
+			return errors.Wrapf(err, "failed to append END_DEFINED_NAMES to %s", filePath)
+		}
 	}
 
 	_, err = f.WriteString(endWorkbook)
@@ -179,19 +504,68 @@ func (wb *Workbook) createMain() error {
 
 // Commit commits the workbook persisting the data to the specified file.
 func (wb *Workbook) Commit() error {
+	return wb.CommitContext(context.Background())
+}
+
+// CommitContext is just like Commit but stops when the context is done, in which case the workbook is aborted: its
+// temporary files are removed and ctx.Err() is returned.
+func (wb *Workbook) CommitContext(ctx context.Context) error {
+	err := wb.commit(ctx)
+	if err != nil && ctx.Err() != nil {
+		wb.abort()
+		return ctx.Err()
+	}
+	return err
+}
+
+func (wb *Workbook) commit(ctx context.Context) error {
 	if wb.committed {
-		return errors.New("can't commit a committed workbook")
+		return errors.Wrap(ErrCommitted, "can't commit the workbook")
+	}
+
+	if wb.isAborted() {
+		return errors.Wrap(ErrAborted, "can't commit the workbook")
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
 	if len(wb.worksheets) == 0 {
-		return errors.New("a workbook needs at least one worksheet")
+		return ErrNoWorksheets
 	}
 
 	if wb.HasPendingWorksheets() {
-		return errors.New("can't commit if there are still pending worksheets")
+		return errors.Wrap(ErrPendingWorksheets, "can't commit the workbook")
+	}
+
+	if wb.activeWorksheet() == nil {
+		return errors.New("a workbook needs at least one visible worksheet")
+	}
+
+	zipOpts, err := newZipOptions(wb.options)
+	if err != nil {
+		return err
+	}
+
+	if wb.options.NoOverwrite {
+		_, err = os.Stat(wb.FilePath)
+		if err == nil {
+			return errors.Wrapf(ErrFileExists, "can't commit the workbook to %s", wb.FilePath)
+		}
+	}
+
+	err = wb.validateDefinedNames()
+	if err != nil {
+		return errors.Wrap(err, "invalid defined names")
+	}
+
+	err = wb.selectActiveWorksheet()
+	if err != nil {
+		return err
 	}
 
-	err := wb.createContentTypes()
+	err = wb.createContentTypes()
 	if err != nil {
 		return errors.Wrap(err, "failed to create the content types file")
 	}
@@ -211,26 +585,167 @@ func (wb *Workbook) Commit() error {
 		return errors.Wrap(err, "failed to create the main file")
 	}
 
-	zip := new(archivex.ZipFile)
-	zip.Create(strings.TrimSuffix(wb.FilePath, path.Ext(wb.FilePath)))
-	zip.AddAll(wb.tempRootDir, false)
-	err = zip.Close()
+	err = wb.createStyles()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the styles file")
+	}
+
+	err = wb.createDocumentProperties()
 	if err != nil {
-		return errors.Wrap(err, "failed to close the zip file")
+		return errors.Wrap(err, "failed to create the document properties files")
 	}
 
-	curPath := fmt.Sprintf("%s.zip", strings.TrimSuffix(wb.FilePath, path.Ext(wb.FilePath)))
-	newPath := wb.FilePath
-	err = os.Rename(curPath, newPath)
+	err = wb.writeFile(ctx, zipOpts)
 	if err != nil {
-		return errors.Wrapf(err, "failed to rename file %s to %s", curPath, newPath)
+		return err
 	}
 
 	wb.committed = true
 
+	// The parts are all in the file now.
+	os.RemoveAll(wb.tempRootDir)
+
+	return nil
+}
+
+// writeFile zips the workbook to a new file next to FilePath, which is then renamed to it. Readers of FilePath either
+// see the previous file or the complete new one, and any other file in the directory is left untouched.
+func (wb *Workbook) writeFile(ctx context.Context, zipOpts zipOptions) (err error) {
+	f, err := createUniqueFile(wb.FilePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a temporary file next to %s", wb.FilePath)
+	}
+	tempPath := f.Name()
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	err = zipDir(ctx, wb.tempRootDir, f, zipOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the zip file")
+	}
+	err = f.Sync()
+	if err != nil {
+		return errors.Wrapf(err, "failed to sync %s", tempPath)
+	}
+	err = f.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to close %s", tempPath)
+	}
+
+	if !wb.options.NoOverwrite {
+		err = os.Rename(tempPath, wb.FilePath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rename file %s to %s", tempPath, wb.FilePath)
+		}
+		return nil
+	}
+
+	// A link, unlike a rename, fails if the file was created since it was checked. Where links aren't supported, the
+	// file is created exclusively and copied to instead, which isn't atomic.
+	err = os.Link(tempPath, wb.FilePath)
+	if os.IsExist(err) {
+		return errors.Wrapf(ErrFileExists, "can't commit the workbook to %s", wb.FilePath)
+	}
+	if err != nil {
+		err = copyFileExclusive(tempPath, wb.FilePath)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.Remove(tempPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove file %s", tempPath)
+	}
+	return nil
+}
+
+// copyFileExclusive copies a file to a new one, failing with ErrFileExists if there's already a file at dst.
+func copyFileExclusive(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", src)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if os.IsExist(err) {
+		return errors.Wrapf(ErrFileExists, "can't copy %s to %s", src, dst)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", dst)
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(dst)
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy %s to %s", src, dst)
+	}
+	err = out.Sync()
+	if err != nil {
+		return errors.Wrapf(err, "failed to sync %s", dst)
+	}
+	err = out.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to close %s", dst)
+	}
 	return nil
 }
 
+// createUniqueFile creates a hidden file, with a name no other file has, in the directory of filePath. Unlike
+// ioutil.TempFile, it's created with the permissions os.Create gives, as it becomes the file at filePath.
+func createUniqueFile(filePath string) (*os.File, error) {
+	dir, base := filepath.Split(filePath)
+	for i := 0; i < 10000; i++ {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", base, rand.Uint32()))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, errors.Errorf("failed to find a unique name for a file next to %s", filePath)
+}
+
+// abort removes the temporary files of the workbook, which can't be used afterwards.
+func (wb *Workbook) abort() {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	wb.aborted = true
+	if wb.tempDirsCreated {
+		os.RemoveAll(wb.tempRootDir)
+	}
+}
+
+func (wb *Workbook) isAborted() bool {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.aborted
+}
+
+// ensureTempDirs creates the temporary directories unless another worksheet already did.
+func (wb *Workbook) ensureTempDirs() error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if wb.aborted {
+		return ErrAborted
+	}
+	if wb.tempDirsCreated {
+		return nil
+	}
+	return wb.createTempDirs()
+}
+
 func (wb *Workbook) createTempDirs() error {
 	if wb.tempDirsCreated {
 		return errors.New("temporary directories already created")
@@ -270,6 +785,16 @@ func (wb *Workbook) createTempDirs() error {
 		return errors.Wrapf(err, "failed to create temporary directory \"%s\"", wb.tempDirs.xlWorksheets)
 	}
 
+	// /docProps
+	wb.tempDirs.docProps = path.Join(wb.tempRootDir, "docProps")
+	err = os.Mkdir(wb.tempDirs.docProps, os.ModeDir|os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary directory \"%s\"", wb.tempDirs.docProps)
+	}
+
+	wb.tempDirs.xlWorksheetsRels = path.Join(wb.tempDirs.xlWorksheets, "_rels")
+	wb.tempDirs.xlTables = path.Join(wb.tempDirs.xl, "tables")
+
 	wb.tempDirsCreated = true
 
 	return nil
@@ -1,16 +1,31 @@
 package xlsx
 
 import (
+	"archive/zip"
+	"compress/flate"
+	"context"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 func Test_Workbook_AddWorksheet_ShouldProperlyAddNewWorksheet_WhenGivenValidArguments(t *testing.T) {
 	wb := NewWorkbook("./spreadsheet.xlsx")
 	wsName := "Sheet 1"
-	ws := wb.AddWorksheet(&WorksheetOptions{
+	ws, err := wb.AddWorksheet(&WorksheetOptions{
 		Name: wsName,
 	})
+	if err != nil {
+		t.Errorf("failed to add the worksheet: %v", err)
+		return
+	}
 	if ws.name != wsName {
 		t.Errorf("worksheet name differs from the one given to AddWorksheet, expected \"%s\", found \"%s\"", wsName, ws.name)
 		return
@@ -21,6 +36,53 @@ func Test_Workbook_AddWorksheet_ShouldProperlyAddNewWorksheet_WhenGivenValidArgu
 	}
 }
 
+func Test_Workbook_AddWorksheet_ShouldGenerateNames_WhenGivenEmptyNames(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	wb.AddWorksheet(&WorksheetOptions{Name: "Sheet2"})
+
+	ws, err := wb.AddWorksheet(&WorksheetOptions{})
+	if err != nil {
+		t.Errorf("failed to add the worksheet: %v", err)
+		return
+	}
+	if ws.name != "Sheet3" {
+		t.Errorf("unexpected generated worksheet name, expected \"Sheet3\", found \"%s\"", ws.name)
+	}
+}
+
+func Test_Workbook_AddWorksheet_ShouldFail_WhenGivenInvalidNames(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	_, err := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	if err != nil {
+		t.Errorf("failed to add the worksheet: %v", err)
+		return
+	}
+
+	for _, name := range []string{"DATA", "A name longer than thirty one chars", "Q1/Q2", "[Data]", "'Data", "History"} {
+		_, err = wb.AddWorksheet(&WorksheetOptions{Name: name})
+		if err == nil {
+			t.Errorf("expected an error when adding the worksheet %q", name)
+		}
+	}
+}
+
+func Test_Workbook_AddWorksheet_ShouldFail_WhenGivenInvalidOptions(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	for _, opts := range []*WorksheetOptions{
+		&WorksheetOptions{State: "Hidden"},
+		&WorksheetOptions{TabColor: "zzz"},
+		&WorksheetOptions{MaxOutlineLevel: maxOutlineLevel + 1},
+	} {
+		_, err := wb.AddWorksheet(opts)
+		if err == nil {
+			t.Errorf("expected an error when adding a worksheet with %+v", opts)
+		}
+	}
+	if len(wb.worksheets) != 0 {
+		t.Errorf("expected no worksheet to be added, found %d", len(wb.worksheets))
+	}
+}
+
 func Test_Workbook_createTempDirs_ShouldProperlyCreateTemporaryDirectories(t *testing.T) {
 	wb := NewWorkbook("./spreadsheet.xlsx")
 
@@ -42,3 +104,474 @@ func Test_Workbook_createTempDirs_ShouldProperlyCreateTemporaryDirectories(t *te
 		return
 	}
 }
+
+func Test_Workbook_SetActiveSheet_ShouldSelectWorksheet_WhenWorksheetIsVisible(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-8.xlsx")
+	lookup, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:  "Lookup",
+		State: WorksheetVeryHidden,
+	})
+	summary, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:     "Summary",
+		TabColor: "00B050",
+	})
+	data, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:        "Data",
+		RightToLeft: true,
+	})
+
+	if wb.activeWorksheet() != summary {
+		t.Error("expected the first visible worksheet to be active by default")
+	}
+
+	err := wb.SetActiveSheet(lookup)
+	if err == nil {
+		t.Error("expected an error when activating a very hidden worksheet")
+	}
+
+	for _, ws := range []*Worksheet{lookup, summary, data} {
+		err = ws.DefineColumns([]*WorksheetColumn{
+			&WorksheetColumn{Key: "name", Value: ws.name},
+		})
+		if err != nil {
+			t.Errorf("failed to define the columns: %v", err)
+			return
+		}
+	}
+
+	// The header of the worksheets is already committed.
+	err = wb.SetActiveSheet(data)
+	if err != nil {
+		t.Errorf("failed to set the active sheet: %v", err)
+		return
+	}
+
+	for _, ws := range []*Worksheet{lookup, summary, data} {
+		err = ws.Commit()
+		if err != nil {
+			t.Errorf("failed to commit worksheet: %v", err)
+			return
+		}
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	err = wb.SetActiveSheet(summary)
+	if !errors.Is(err, ErrCommitted) {
+		t.Errorf("expected ErrCommitted, found: %v", err)
+	}
+
+	content, err := readPart(wb, "xl/workbook.xml")
+	if err != nil {
+		t.Errorf("failed to read the workbook file: %v", err)
+		return
+	}
+	for _, expected := range []string{
+		`activeTab="2"`,
+		`<sheet name="Lookup" sheetId="1" state="veryHidden" r:id="rId1"/>`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("workbook is missing %s, found: %s", expected, content)
+		}
+	}
+
+	content, err = readPart(wb, "xl/worksheets/"+data.fileName)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<sheetView tabSelected="1" rightToLeft="1" workbookViewId="0"/>`) {
+		t.Errorf("unexpected sheet view, found: %s", content)
+	}
+
+	content, err = readPart(wb, "xl/worksheets/"+summary.fileName)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<sheetPr><tabColor rgb="FF00B050"/></sheetPr><sheetViews><sheetView tabSelected="0" workbookViewId="0"/>`) {
+		t.Errorf("unexpected sheet properties, found: %s", content)
+	}
+}
+
+func Test_Workbook_CommitContext_ShouldAbort_WhenContextIsDone(t *testing.T) {
+	filePath := path.Join(os.TempDir(), "xlsx-canceled.xlsx")
+	wb := NewWorkbook(filePath)
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = "value"
+	ws.CommitRows()
+	ws.Commit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := wb.CommitContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, found: %v", err)
+	}
+
+	_, err = os.Stat(wb.tempRootDir)
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the temporary files to be removed, found: %v", err)
+	}
+	_, err = os.Stat(filePath)
+	if !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written, found: %v", err)
+	}
+
+	err = wb.Commit()
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected ErrAborted, found: %v", err)
+	}
+}
+
+func Test_Worksheet_CommitRowsContext_ShouldAbort_WhenDeadlineIsExceeded(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+	})
+	for i := 0; i < 10; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = i
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	err := ws.CommitRowsContext(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, found: %v", err)
+	}
+
+	_, err = os.Stat(wb.tempRootDir)
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the temporary files to be removed, found: %v", err)
+	}
+
+	_, err = wb.AddWorksheet(&WorksheetOptions{})
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected ErrAborted, found: %v", err)
+	}
+}
+
+func Test_Workbook_Commit_ShouldWriteWorksheetsFilledConcurrently(t *testing.T) {
+	filePath := path.Join(os.TempDir(), "xlsx-concurrent.xlsx")
+	defer os.Remove(filePath)
+	wb := NewWorkbook(filePath)
+
+	worksheets := make([]*Worksheet, 8)
+	for i := 0; i < len(worksheets); i++ {
+		ws, err := wb.AddWorksheet(&WorksheetOptions{Name: fmt.Sprintf("Data %d", i), Overflow: true, AsTable: &TableOptions{}})
+		if err != nil {
+			t.Errorf("failed to add worksheet: %v", err)
+			return
+		}
+		ws.rowsLimit = 300
+		worksheets[i] = ws
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(worksheets))
+	for i := 0; i < len(worksheets); i++ {
+		wg.Add(1)
+		go func(i int, ws *Worksheet) {
+			defer wg.Done()
+			errs[i] = fillWorksheet(ws, 1000, fmt.Sprintf("0.%0*d", i+1, 0))
+		}(i, worksheets[i])
+	}
+	wg.Wait()
+	for i := 0; i < len(errs); i++ {
+		if errs[i] != nil {
+			t.Errorf("failed to fill worksheet %d: %v", i, errs[i])
+			return
+		}
+	}
+
+	err := wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	// Every worksheet overflows to 3 more, each with its own table.
+	if len(wb.worksheets) != 32 || wb.tablesCount != 32 {
+		t.Errorf("expected 32 worksheets and tables, found %d and %d", len(wb.worksheets), wb.tablesCount)
+	}
+	if len(wb.styles.numFmts) != len(worksheets) {
+		t.Errorf("expected %d number formats, found: %v", len(worksheets), wb.styles.numFmts)
+	}
+}
+
+func fillWorksheet(ws *Worksheet, rows int, format string) error {
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+		&WorksheetColumn{Key: "amount", Value: "Amount", Style: &Style{NumberFormat: format}},
+	})
+	if err != nil {
+		return err
+	}
+	for i := 0; i < rows; i++ {
+		row, err := ws.AddRow()
+		if err != nil {
+			return err
+		}
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = i
+		cell, _ = row.AddCellWithKey("amount")
+		cell.Value = float64(i) / 3
+		if (i+1)%100 == 0 || i == rows-1 {
+			err = ws.CommitRows()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return ws.Commit()
+}
+
+func Test_Workbook_Commit_ShouldCompressParts_WhenGivenCompressionOptions(t *testing.T) {
+	filePath := path.Join(os.TempDir(), "xlsx-compression.xlsx")
+	defer os.Remove(filePath)
+	wb := NewWorkbookWithOptions(filePath, &WorkbookOptions{CompressionLevel: flate.BestSpeed, CompressionWorkers: 2})
+	for i := 0; i < 3; i++ {
+		ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+		err := fillWorksheet(ws, 500, "0.00")
+		if err != nil {
+			t.Errorf("failed to fill worksheet: %v", err)
+			return
+		}
+	}
+	err := wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		t.Errorf("failed to open the zip file: %v", err)
+		return
+	}
+	defer r.Close()
+	if r.File[0].Name != "[Content_Types].xml" {
+		t.Errorf("expected the content types to come first, found: %s", r.File[0].Name)
+	}
+	// Reading every part has the reader check their sizes and CRC-32.
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Errorf("failed to open %s: %v", f.Name, err)
+			continue
+		}
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Errorf("failed to decompress %s: %v", f.Name, err)
+		} else if f.Name == "xl/worksheets/sheet3.xml" && !strings.Contains(string(content), `<c r="A501" s="0" t="n"><v>499</v></c>`) {
+			t.Errorf("unexpected content for %s: %s", f.Name, content)
+		}
+	}
+}
+
+func Test_Workbook_Commit_ShouldStoreParts_WhenNoCompressionIsSet(t *testing.T) {
+	filePath := path.Join(os.TempDir(), "xlsx-no-compression.xlsx")
+	defer os.Remove(filePath)
+	wb := NewWorkbookWithOptions(filePath, &WorkbookOptions{NoCompression: true, CompressionLevel: flate.BestCompression})
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+	err := fillWorksheet(ws, 500, "0.00")
+	if err != nil {
+		t.Errorf("failed to fill worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		t.Errorf("failed to open the zip file: %v", err)
+		return
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.CompressedSize64 < f.UncompressedSize64 {
+			t.Errorf("expected %s not to be compressed, found %d bytes for %d", f.Name, f.CompressedSize64, f.UncompressedSize64)
+		}
+	}
+}
+
+// readPart returns a part of the file written by Commit, such as xl/workbook.xml.
+func readPart(wb *Workbook, name string) ([]byte, error) {
+	r, err := zip.OpenReader(wb.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, errors.Errorf("%s is missing from %s", name, wb.FilePath)
+}
+
+func Test_Workbook_Commit_ShouldFail_WhenGivenInvalidCompressionLevel(t *testing.T) {
+	wb := NewWorkbookWithOptions("./spreadsheet.xlsx", &WorkbookOptions{CompressionLevel: 10})
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+	fillWorksheet(ws, 1, "0.00")
+
+	err := wb.Commit()
+	if err == nil || !strings.Contains(err.Error(), "invalid compression level 10") {
+		t.Errorf("expected an invalid compression level error, found: %v", err)
+	}
+}
+
+func Test_Workbook_Commit_ShouldOnlyWriteFilePath(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "xlsx-output-")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(path.Join(dir, "report.zip"), []byte("unrelated"), 0666)
+	ioutil.WriteFile(path.Join(dir, "report.xlsx"), []byte("previous"), 0666)
+
+	wb := NewWorkbook(path.Join(dir, "report.xlsx"))
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+	fillWorksheet(ws, 1, "0.00")
+	err := wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	content, _ := ioutil.ReadFile(path.Join(dir, "report.zip"))
+	if string(content) != "unrelated" {
+		t.Errorf("expected report.zip to be left untouched, found: %s", content)
+	}
+	_, err = zip.OpenReader(path.Join(dir, "report.xlsx"))
+	if err != nil {
+		t.Errorf("expected report.xlsx to be replaced, found: %v", err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("expected no temporary file to be left, found %d files", len(files))
+	}
+	_, err = os.Stat(wb.tempRootDir)
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the temporary directory to be removed, found: %v", err)
+	}
+}
+
+func Test_Workbook_Commit_ShouldFail_WhenFileExistsAndNoOverwriteIsSet(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "xlsx-output-")
+	defer os.RemoveAll(dir)
+	filePath := path.Join(dir, "report.xlsx")
+	ioutil.WriteFile(filePath, []byte("previous"), 0666)
+
+	wb := NewWorkbookWithOptions(filePath, &WorkbookOptions{NoOverwrite: true})
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{})
+	fillWorksheet(ws, 1, "0.00")
+	err := wb.Commit()
+	if !errors.Is(err, ErrFileExists) {
+		t.Errorf("expected ErrFileExists, found: %v", err)
+	}
+	content, _ := ioutil.ReadFile(filePath)
+	if string(content) != "previous" {
+		t.Errorf("expected the file to be left untouched, found: %s", content)
+	}
+
+	os.Remove(filePath)
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected no temporary file to be left, found %d files", len(files))
+	}
+}
+
+func Test_Worksheet_CommitRows_ShouldFailWithErrAborted_WhenAnotherWorksheetIsCanceled(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	other, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Other"})
+	for _, value := range []string{"committed", "pending"} {
+		row, _ := other.AddRow()
+		cell, _ := row.AddCell()
+		cell.Value = value
+		if value == "committed" {
+			other.CommitRows()
+		}
+	}
+
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.AddRow()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ws.CommitRowsContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, found: %v", err)
+	}
+
+	err = other.CommitRows()
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected ErrAborted when committing rows, found: %v", err)
+	}
+	_, err = other.AddRow()
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("expected ErrAborted when adding a row, found: %v", err)
+	}
+}
+
+func Test_Worksheet_abortedError_ShouldReplaceErrors_WhenWorkbookIsAborted(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	ioErr := errors.New("no such file or directory")
+	if err := ws.abortedError(ioErr, "can't commit"); err != ioErr {
+		t.Errorf("expected the error to be kept, found: %v", err)
+	}
+	wb.abort()
+	if err := ws.abortedError(ioErr, "can't commit"); !errors.Is(err, ErrAborted) {
+		t.Errorf("expected ErrAborted, found: %v", err)
+	}
+}
+
+func Test_copyFileExclusive_ShouldNotOverwriteFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "xlsx-output-")
+	defer os.RemoveAll(dir)
+	src, dst := path.Join(dir, "src"), path.Join(dir, "dst")
+	ioutil.WriteFile(src, []byte("new"), 0666)
+
+	err := copyFileExclusive(src, dst)
+	if err != nil {
+		t.Errorf("failed to copy the file: %v", err)
+		return
+	}
+	content, _ := ioutil.ReadFile(dst)
+	if string(content) != "new" {
+		t.Errorf("unexpected copy, found: %s", content)
+	}
+
+	ioutil.WriteFile(dst, []byte("previous"), 0666)
+	err = copyFileExclusive(src, dst)
+	if !errors.Is(err, ErrFileExists) {
+		t.Errorf("expected ErrFileExists, found: %v", err)
+	}
+	content, _ = ioutil.ReadFile(dst)
+	if string(content) != "previous" {
+		t.Errorf("expected the file to be left untouched, found: %s", content)
+	}
+}
@@ -1,17 +1,33 @@
 package xlsx
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/pkg/errors"
 )
 
+const (
+	maxRows    = 1048576
+	maxColumns = 16384
+	// maxCellLength is the number of characters a cell can hold.
+	maxCellLength = 32767
+	// maxOutlineLevel is the deepest outline level Excel supports.
+	maxOutlineLevel = 7
+	// maxExactInteger is the largest unsigned integer written as a number, Excel stores numbers as doubles which can't
+	// hold every larger integer exactly.
+	maxExactInteger = 1 << 53
+)
+
 // Worksheet represents a worksheet in a workbook.
 type Worksheet struct {
 	workbook          *Workbook
@@ -24,31 +40,86 @@ type Worksheet struct {
 	pendingRows       []*Row
 	rowsCount         int
 	started           bool
-	columns           []*WorksheetColumn
+	// tabSelectedOffset is where the tabSelected value is in the worksheet file.
+	tabSelectedOffset  int64
+	columns            []*WorksheetColumn
+	headerRows         int
+	footerRows         int
+	options            WorksheetOptions
+	autoFilter         *worksheetRange
+	mergedCells        []*cellRange
+	conditionalFormats []*conditionalFormatting
+	dataValidations    []*dataValidation
+	table              *table
+	relationships      []*relationship
+	rowsLimit          int
+	// next is the worksheet the rows go to once this one is full, origin is the first worksheet of that chain and part
+	// its position in it.
+	next   *Worksheet
+	origin *Worksheet
+	part   int
+}
+
+type worksheetRange struct {
+	ref      string
+	absolute string
 }
 
 // WorksheetColumn represents a column in a worksheet.
 type WorksheetColumn struct {
 	Key   string
 	Value string
+	// Validation restricts the values of every data row in the column.
+	Validation *DataValidation
+	// OutlineLevel groups the column with its neighbours at the same level, from 0 to 7.
+	OutlineLevel int
+	// Collapsed marks the column as the summary of a collapsed group.
+	Collapsed bool
+	Hidden    bool
+	// Style is applied to the data cells of the column, but not to its header.
+	Style *Style
+	// Width is the width of the column in characters, the default width is used when it's 0.
+	Width float64
 }
 
 // DefineColumns defines the worksheet columns. It's optional.
 func (ws *Worksheet) DefineColumns(columns []*WorksheetColumn) error {
 	if ws.committed {
-		return errors.New("can't define columns on a committed worksheet")
+		return errors.Wrap(ErrCommitted, "can't define columns on the worksheet")
 	}
 
 	if ws.rowsCommittedOnce {
 		return errors.New("can't define columns if rows have been committed")
 	}
 
+	if len(columns) > maxColumns {
+		return &LimitError{Sheet: ws.name, Limit: ColumnsLimit, Max: maxColumns}
+	}
+
+	var validations []*dataValidation
+	for i := 0; i < len(columns); i++ {
+		if columns[i].OutlineLevel < 0 || columns[i].OutlineLevel > maxOutlineLevel {
+			return errors.Errorf("the outline level of column %s must be within 0 and %d", columns[i].Key, maxOutlineLevel)
+		}
+		if columns[i].Validation == nil {
+			continue
+		}
+		dv, err := createDataValidation(columns[i].Validation)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s validation on column %s", columns[i].Validation.Type, columns[i].Key)
+		}
+		dv.columnKey = columns[i].Key
+		validations = append(validations, dv)
+	}
+	ws.dataValidations = append(ws.dataValidations, validations...)
+
 	ws.columns = columns
 
 	row, err := ws.AddRow()
 	if err != nil {
 		return errors.Wrap(err, "failed to create the header row")
 	}
+	ws.headerRows = row.index + 1
 	for i := 0; i < len(ws.columns); i++ {
 		cell, err := row.AddCellWithKey(ws.columns[i].Key)
 		cell.Value = ws.columns[i].Value
@@ -67,16 +138,75 @@ func (ws *Worksheet) DefineColumns(columns []*WorksheetColumn) error {
 
 // AddRow adds a new row to the worksheet.
 func (ws *Worksheet) AddRow() (*Row, error) {
+	return ws.AddRowWithOptions(&RowOptions{})
+}
+
+// AddRowWithOptions is just like AddRow but allows the row to be part of an outline group.
+func (ws *Worksheet) AddRowWithOptions(opts *RowOptions) (*Row, error) {
+	if ws.next != nil {
+		return ws.next.AddRowWithOptions(opts)
+	}
+
+	if ws.rowsCount >= ws.dataRowsLimit() && ws.options.Overflow && !ws.committed {
+		next, err := ws.overflow()
+		if err != nil {
+			return nil, err
+		}
+		return next.AddRowWithOptions(opts)
+	}
+
+	return ws.addRow(ws.rowsCount, opts)
+}
+
+// AddRowAt adds a new row at the zero based index, the rows skipped are left empty. Rows must be added from top to
+// bottom.
+func (ws *Worksheet) AddRowAt(index int) (*Row, error) {
+	if ws.next != nil {
+		return ws.next.AddRowAt(index)
+	}
+
+	if index < ws.rowsCount {
+		return nil, errors.Wrapf(ErrOutOfOrder, "can't add row %d after row %d", index+1, ws.rowsCount)
+	}
+
+	return ws.addRow(index, &RowOptions{})
+}
+
+// dataRowsLimit is the number of rows the worksheet takes before overflowing, which leaves room for the totals row
+// added to its table on commit.
+func (ws *Worksheet) dataRowsLimit() int {
+	if ws.options.AsTable != nil && ws.options.AsTable.ShowTotalsRow {
+		return ws.rowsLimit - 1
+	}
+	return ws.rowsLimit
+}
+
+func (ws *Worksheet) addRow(index int, opts *RowOptions) (*Row, error) {
 	if ws.committed {
-		return nil, errors.New("can't add rows to a committed worksheet")
+		return nil, errors.Wrap(ErrCommitted, "can't add rows to the worksheet")
+	}
+
+	if ws.workbook.isAborted() {
+		return nil, errors.Wrap(ErrAborted, "can't add rows to the worksheet")
+	}
+
+	if opts.OutlineLevel < 0 || opts.OutlineLevel > ws.options.MaxOutlineLevel {
+		return nil, errors.Errorf("the outline level must be within 0 and %d, the MaxOutlineLevel of the worksheet", ws.options.MaxOutlineLevel)
+	}
+
+	if index >= ws.rowsLimit {
+		return nil, &LimitError{Sheet: ws.name, Limit: RowsLimit, Max: ws.rowsLimit}
 	}
 
 	row := &Row{
-		worksheet: ws,
-		index:     ws.rowsCount,
+		worksheet:    ws,
+		index:        index,
+		outlineLevel: opts.OutlineLevel,
+		collapsed:    opts.Collapsed,
+		hidden:       opts.Hidden,
 	}
 	ws.pendingRows = append(ws.pendingRows, row)
-	ws.rowsCount = ws.rowsCount + 1
+	ws.rowsCount = index + 1
 
 	if ws.columns != nil {
 		row.cellsMap = make(map[string]*Cell)
@@ -101,98 +231,350 @@ func (ws *Worksheet) start() error {
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(startWorksheet)
+	err = ws.writeHead(f)
+	if err != nil {
+		// The worksheet is started again, from a new file, when rows are next committed.
+		f.Close()
+		os.Remove(ws.filePath)
+		return err
+	}
+	ws.started = true
+
+	return nil
+}
+
+// writeHead writes the parts of the worksheet that precede its rows, so they must be known once the first rows are
+// committed.
+func (ws *Worksheet) writeHead(f *os.File) error {
+	_, err := f.WriteString(startWorksheet)
 	if err != nil {
 		return errors.Wrapf(err, "failed to append START_WORKSHEET to file %s", ws.filePath)
 	}
+	sheetPr, err := ws.sheetPr()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(sheetPr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the sheet properties to file %s", ws.filePath)
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the size of file %s", ws.filePath)
+	}
+	ws.tabSelectedOffset = offset + int64(tabSelectedOffset)
+	_, err = f.WriteString(sheetViewsFormat(ws.options.RightToLeft, ws.frozenPane()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the sheet views to file %s", ws.filePath)
+	}
+	_, err = f.WriteString(sheetFormatPrFormat(ws.options.MaxOutlineLevel, ws.columnsOutlineLevel()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to append the sheet format properties to file %s", ws.filePath)
+	}
+	err = ws.writeColumns(f)
+	if err != nil {
+		return err
+	}
 	_, err = f.WriteString(startWorksheetData)
 	if err != nil {
 		return errors.Wrapf(err, "failed to append START_WORKSHEET_DATA to file %s", ws.filePath)
 	}
 
-	ws.started = true
-
 	return nil
 }
 
-func (ws *Worksheet) createRow(row *Row) error {
-	// TODO: Benchmark how error checking affects performance
-	f, err := os.OpenFile(ws.filePath, os.O_APPEND|os.O_WRONLY, os.ModePerm)
-	if err != nil {
-		return errors.Wrapf(err, "failed to open file %s to append a new row", ws.filePath)
+func (ws *Worksheet) sheetPr() (string, error) {
+	var content string
+	if ws.options.TabColor != "" {
+		color, err := normalizeColor(ws.options.TabColor)
+		if err != nil {
+			return "", errors.Wrap(err, "invalid tab color")
+		}
+		content += tabColorFormat(color)
 	}
-	defer f.Close()
+	if ws.options.SummaryRowsAbove {
+		content += outlinePrFormat(false)
+	}
+	if ws.options.PageSetup != nil && ws.options.PageSetup.FitToWidth > 0 {
+		content += pageSetUpPrFormat(true)
+	}
+	return sheetPrFormat(content), nil
+}
+
+func (ws *Worksheet) hidden() bool {
+	return ws.options.State == WorksheetHidden || ws.options.State == WorksheetVeryHidden
+}
+
+func (ws *Worksheet) columnsOutlineLevel() int {
+	level := 0
+	for i := 0; i < len(ws.columns); i++ {
+		if ws.columns[i].OutlineLevel > level {
+			level = ws.columns[i].OutlineLevel
+		}
+	}
+	return level
+}
 
-	_, err = f.WriteString(startRowFormat(row.index))
+func (ws *Worksheet) writeColumns(f *os.File) error {
+	var cols string
+	for i := 0; i < len(ws.columns); i++ {
+		c := ws.columns[i]
+		if c.OutlineLevel == 0 && !c.Collapsed && !c.Hidden && c.Style == nil && c.Width <= 0 {
+			continue
+		}
+		cols += columnFormat(i+1, ws.workbook.styles.cellStyle(0, c.Style), c.Width, c.OutlineLevel, c.Hidden, c.Collapsed)
+	}
+	if cols == "" {
+		return nil
+	}
+
+	_, err := f.WriteString(startColumns + cols + endColumns)
 	if err != nil {
-		return errors.Wrapf(err, "failed to append a new row to file %s", ws.filePath)
+		return errors.Wrapf(err, "failed to append the columns to file %s", ws.filePath)
+	}
+
+	return nil
+}
+
+func (ws *Worksheet) frozenPane() string {
+	rows := ws.options.FreezeRows
+	if ws.options.FreezeHeader && ws.headerRows > rows {
+		rows = ws.headerRows
+	}
+	columns := ws.options.FreezeColumns
+	if rows < 0 {
+		rows = 0
+	}
+	if columns < 0 {
+		columns = 0
+	}
+	return frozenPaneFormat(rows, columns)
+}
+
+// columnIndex returns the index of the column with the given key, or -1 if there's no such column.
+func (ws *Worksheet) columnIndex(key string) int {
+	for i := 0; i < len(ws.columns); i++ {
+		if ws.columns[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnDataRange is the range covering a column from the row after the header to the last data row. It's empty if
+// no data rows were committed.
+func (ws *Worksheet) columnDataRange(key string) string {
+	x := ws.columnIndex(key)
+	y1, y2 := ws.headerRows, ws.rowsCount-ws.footerRows-1
+	if x < 0 || y2 < y1 {
+		return ""
 	}
+	return createRangeFromCoords(x, y1, x, y2)
+}
+
+// columnsRange is the range covering the header written by DefineColumns and every row after it.
+func (ws *Worksheet) columnsRange() *worksheetRange {
+	x1, y1 := 0, ws.headerRows-1
+	x2, y2 := len(ws.columns)-1, ws.rowsCount-1
+	return &worksheetRange{
+		ref:      createRangeFromCoords(x1, y1, x2, y2),
+		absolute: createAbsoluteRangeFromCoords(ws.name, x1, y1, x2, y2),
+	}
+}
+
+// createRow appends a row to the worksheet file. The row is built in memory first, so a cell that can't be written
+// leaves the file untouched.
+func (ws *Worksheet) createRow(row *Row) error {
+	// TODO: Benchmark how error checking affects performance
+	var b strings.Builder
+	b.WriteString(startRowFormat(row.index, row.outlineLevel, row.hidden, row.collapsed))
+
+	styles := ws.workbook.styles
 
 	// TODO: Use reflection to check the type and create the appropriate kind of cell
-	if ws.columns == nil {
+	// Cells without a value are omitted, unless they have a style of their own. Missing cells of a column still get
+	// its style as it's set on the column itself.
+	if row.raw != nil {
+		b.Write(row.raw)
+	} else if ws.columns == nil {
 		for i := 0; i < len(row.cells); i++ {
 			cell := row.cells[i]
-			f.WriteString(cellFormat(cell.identifier, fmt.Sprint(cell.Value)))
+			var value string
+			if cell.Value != nil {
+				value = fmt.Sprint(cell.Value)
+			}
+			if value == "" {
+				if cell.Style != nil {
+					b.WriteString(emptyCellFormat(cell.identifier, styles.cellStyle(0, cell.Style)))
+				}
+				continue
+			}
+			if len(value) > maxCellLength && utf8.RuneCountInString(value) > maxCellLength {
+				return newCellError(cell, &LimitError{Sheet: ws.name, Limit: CellLengthLimit, Max: maxCellLength})
+			}
+			b.WriteString(cellFormat(cell.identifier, value, styles.cellStyle(0, cell.Style)))
 		}
 	} else {
-		lastCellIndex := 0
 		for i := 0; i < len(ws.columns); i++ {
 			cell, ok := row.cellsMap[ws.columns[i].Key]
+			if !ok {
+				continue
+			}
+
+			// The header keeps the default style so it stays locked when the data cells aren't.
+			style := cell.Style
+			if style == nil && row.index >= ws.headerRows {
+				style = ws.columns[i].Style
+			}
+
+			valuer, ok := cell.Value.(driver.Valuer)
 			if ok {
-				valuer, ok := cell.Value.(driver.Valuer)
-				if ok {
-					value, err := valuer.Value()
-					if err != nil {
-						return errors.Wrap(err, "failed to retrieve the Value of a driver.Valuer")
-					}
-					if value == nil {
-						lastCellIndex++
-						f.WriteString(cellFormat(createIdentifierFromCoords(lastCellIndex, row.index), ""))
-						continue
-					}
-					cell.Value = value
+				value, err := valuer.Value()
+				if err != nil {
+					return newCellError(cell, errors.Wrap(err, "failed to retrieve the Value of a driver.Valuer"))
 				}
+				cell.Value = value
+			}
 
-				t := reflect.TypeOf(cell.Value)
-				k := t.Kind()
-				switch k {
-				case reflect.String, reflect.Bool:
-					f.WriteString(cellFormat(cell.identifier, fmt.Sprint(cell.Value)))
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
-					f.WriteString(numberCellFormat(cell.identifier, cell.Value))
-				case reflect.Struct:
-					if t.String() != "time.Time" {
-						return errors.Errorf("%s is not supported in a cell", t.String())
-					}
-					f.WriteString(dateCellFormat(cell.identifier, cell.Value.(time.Time)))
-				default:
-					return errors.Errorf("%s is not supported in a cell", k.String())
+			if cell.Value == nil || cell.Value == "" {
+				if cell.Style != nil {
+					b.WriteString(emptyCellFormat(cell.identifier, styles.cellStyle(0, cell.Style)))
 				}
+				continue
+			}
 
-				lastCellIndex = cell.index
-			} else {
-				lastCellIndex++
-				f.WriteString(cellFormat(createIdentifierFromCoords(lastCellIndex, row.index), ""))
+			t := reflect.TypeOf(cell.Value)
+			k := t.Kind()
+			switch k {
+			case reflect.String, reflect.Bool:
+				if d, ok := cell.Value.(decimalText); ok {
+					b.WriteString(numberCellFormat(cell.identifier, d, styles.cellStyle(0, style)))
+					continue
+				}
+				value := fmt.Sprint(cell.Value)
+				if len(value) > maxCellLength && utf8.RuneCountInString(value) > maxCellLength {
+					return newCellError(cell, &LimitError{Sheet: ws.name, Limit: CellLengthLimit, Max: maxCellLength})
+				}
+				b.WriteString(cellFormat(cell.identifier, value, styles.cellStyle(0, style)))
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+				b.WriteString(numberCellFormat(cell.identifier, cell.Value, styles.cellStyle(0, style)))
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				// Integers past maxExactInteger are written as text, so they keep every digit.
+				if n := reflect.ValueOf(cell.Value).Uint(); n > maxExactInteger {
+					b.WriteString(cellFormat(cell.identifier, strconv.FormatUint(n, 10), styles.cellStyle(0, style)))
+				} else {
+					b.WriteString(numberCellFormat(cell.identifier, cell.Value, styles.cellStyle(0, style)))
+				}
+			case reflect.Struct:
+				if t.String() != "time.Time" {
+					return newCellError(cell, errors.Wrapf(ErrUnsupportedType, "%s is not supported in a cell", t.String()))
+				}
+				b.WriteString(dateCellFormat(cell.identifier, cell.Value.(time.Time), styles.cellStyle(dateNumFmtID, style)))
+			default:
+				return newCellError(cell, errors.Wrapf(ErrUnsupportedType, "%s is not supported in a cell", k.String()))
 			}
 		}
 	}
 
-	_, err = f.WriteString(endRow)
+	b.WriteString(endRow)
+
+	f, err := os.OpenFile(ws.filePath, os.O_APPEND|os.O_WRONLY, os.ModePerm)
 	if err != nil {
-		return errors.Wrapf(err, "failed to end row on file %s", ws.filePath)
+		return errors.Wrapf(err, "failed to open file %s to append a new row", ws.filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(b.String())
+	if err != nil {
+		return errors.Wrapf(err, "failed to append a new row to file %s", ws.filePath)
+	}
+
+	return f.Close()
+}
+
+// addValues adds a row with the values as the cells of the columns with the given keys, nil values leave their cells
+// empty. The values are checked first, so an invalid one doesn't leave a partial row behind.
+func (ws *Worksheet) addValues(keys []string, values []interface{}) error {
+	for i := 0; i < len(values); i++ {
+		err := ws.checkCellValue(values[i])
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for column %s", keys[i])
+		}
+	}
+
+	row, err := ws.AddRow()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(values); i++ {
+		if values[i] == nil {
+			continue
+		}
+		cell, err := row.AddCellWithKey(keys[i])
+		if err != nil {
+			row.discard()
+			return err
+		}
+		cell.Value = values[i]
 	}
 
 	return nil
 }
 
-func (ws *Worksheet) end() error {
+// checkCellValue returns the error createRow would return for the value of a cell of a worksheet with columns, so
+// values can be checked before their row is added. The value of a driver.Valuer is only checked once it's written.
+func (ws *Worksheet) checkCellValue(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return nil
+	}
+
+	t := reflect.TypeOf(value)
+	k := t.Kind()
+	switch k {
+	case reflect.String:
+		value := reflect.ValueOf(value).String()
+		if len(value) > maxCellLength && utf8.RuneCountInString(value) > maxCellLength {
+			return &LimitError{Sheet: ws.name, Limit: CellLengthLimit, Max: maxCellLength}
+		}
+		return nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Struct:
+		if t.String() != "time.Time" {
+			return errors.Wrapf(ErrUnsupportedType, "%s is not supported in a cell", t.String())
+		}
+		return nil
+	default:
+		return errors.Wrapf(ErrUnsupportedType, "%s is not supported in a cell", k.String())
+	}
+}
+
+func (ws *Worksheet) end(ctx context.Context) error {
 	if !ws.started {
 		return errors.New("can't end a worksheet if it has not been started yet")
 	}
 
+	if ws.options.PageSetup != nil {
+		err := validatePageSetup(ws.options.PageSetup)
+		if err != nil {
+			return errors.Wrap(err, "invalid page setup")
+		}
+	}
+
+	if ws.options.AsTable != nil {
+		err := ws.prepareTable(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare the table")
+		}
+	}
+
 	f, err := os.OpenFile(ws.filePath, os.O_APPEND|os.O_WRONLY, os.ModePerm)
 	if err != nil {
-		return errors.Wrapf(err, "failed to open file %s to append a new row", ws.filePath)
+		return errors.Wrapf(err, "failed to open file %s to end the worksheet", ws.filePath)
 	}
 	defer f.Close()
 
@@ -200,29 +582,162 @@ func (ws *Worksheet) end() error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to append END_WORKSHEET_DATA to file %s", ws.filePath)
 	}
+
+	err = ws.writeSheetProtection(f)
+	if err != nil {
+		return err
+	}
+
+	// A table carries its own auto filter, the worksheet one would overlap it.
+	if ws.options.AutoFilter && ws.table == nil && len(ws.columns) > 0 {
+		ws.autoFilter = ws.columnsRange()
+		_, err = f.WriteString(autoFilterFormat(ws.autoFilter.ref))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the auto filter to file %s", ws.filePath)
+		}
+	}
+
+	err = ws.writeMergeCells(f)
+	if err != nil {
+		return err
+	}
+
+	err = ws.writeConditionalFormatting(f)
+	if err != nil {
+		return err
+	}
+
+	err = ws.writeDataValidations(f)
+	if err != nil {
+		return err
+	}
+
+	err = ws.writePageSetup(f)
+	if err != nil {
+		return err
+	}
+
+	err = ws.writeTableParts(f)
+	if err != nil {
+		return err
+	}
+
 	_, err = f.WriteString(endWorksheet)
 	if err != nil {
 		return errors.Wrapf(err, "failed to append END_WORKSHEET to file %s", ws.filePath)
 	}
 
+	err = ws.createRelationships()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the worksheet relationships file")
+	}
+
+	return nil
+}
+
+func (ws *Worksheet) createRelationships() error {
+	if len(ws.relationships) == 0 {
+		return nil
+	}
+
+	err := os.MkdirAll(ws.workbook.tempDirs.xlWorksheetsRels, os.ModeDir|os.ModePerm)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create temporary directory \"%s\"", ws.workbook.tempDirs.xlWorksheetsRels)
+	}
+
+	filePath := path.Join(ws.workbook.tempDirs.xlWorksheetsRels, ws.fileName+".rels")
+	f, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", filePath)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(startWorksheetRels)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append START_WORKSHEET_RELS to %s", filePath)
+	}
+
+	for i := 0; i < len(ws.relationships); i++ {
+		r := ws.relationships[i]
+		_, err = f.WriteString(relationshipFormat(i+1, r.relType, r.target))
+		if err != nil {
+			return errors.Wrapf(err, "failed to append the relationship with the target %s to %s", r.target, filePath)
+		}
+	}
+
+	_, err = f.WriteString(endWorksheetRels)
+	if err != nil {
+		return errors.Wrapf(err, "failed to append END_WORKSHEET_RELS to %s", filePath)
+	}
+
 	return nil
 }
 
-// CommitRows commits rows stored in memory.
+// addRelationship adds a relationship to the worksheet part and returns its id.
+func (ws *Worksheet) addRelationship(relType, target string) int {
+	ws.relationships = append(ws.relationships, &relationship{
+		relType: relType,
+		target:  target,
+	})
+	return len(ws.relationships)
+}
+
+// CommitRows commits rows stored in memory, including the ones that went to the overflow worksheets.
 func (ws *Worksheet) CommitRows() error {
+	return ws.CommitRowsContext(context.Background())
+}
+
+// CommitRowsContext is just like CommitRows but stops when the context is done, in which case the whole workbook is
+// aborted: its temporary files are removed and ctx.Err() is returned. Every other worksheet, including the ones written
+// concurrently, then fails with ErrAborted.
+func (ws *Worksheet) CommitRowsContext(ctx context.Context) error {
+	err := ws.commitRowsChain(ctx)
+	if err != nil && ctx.Err() != nil {
+		ws.workbook.abort()
+		return ctx.Err()
+	}
+	return ws.abortedError(err, "can't commit rows from the worksheet")
+}
+
+// abortedError replaces the error of a write with ErrAborted if the workbook was aborted meanwhile, as it's likely the
+// result of its files being removed.
+func (ws *Worksheet) abortedError(err error, message string) error {
+	if err != nil && !errors.Is(err, ErrAborted) && ws.workbook.isAborted() {
+		return errors.Wrap(ErrAborted, message)
+	}
+	return err
+}
+
+func (ws *Worksheet) commitRowsChain(ctx context.Context) error {
+	if ws.next != nil {
+		if len(ws.pendingRows) > 0 {
+			err := ws.commitRows(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		return ws.next.commitRowsChain(ctx)
+	}
+
+	return ws.commitRows(ctx)
+}
+
+func (ws *Worksheet) commitRows(ctx context.Context) error {
 	if ws.committed {
-		return errors.New("can't commit rows from a committed worksheet")
+		return errors.Wrap(ErrCommitted, "can't commit rows from the worksheet")
+	}
+
+	if ws.workbook.isAborted() {
+		return errors.Wrap(ErrAborted, "can't commit rows from the worksheet")
 	}
 
 	if len(ws.pendingRows) == 0 {
-		return errors.New("there are no rows to commit")
+		return ErrNoRows
 	}
 
-	if !ws.workbook.tempDirsCreated {
-		err := ws.workbook.createTempDirs()
-		if err != nil {
-			return errors.Wrap(err, "failed to create temporary directories")
-		}
+	err := ws.workbook.ensureTempDirs()
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directories")
 	}
 
 	if !ws.started {
@@ -233,11 +748,15 @@ func (ws *Worksheet) CommitRows() error {
 	}
 
 	for len(ws.pendingRows) > 0 {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
 		row := ws.pendingRows[0]
 		ws.pendingRows = ws.pendingRows[1:]
-		err := ws.createRow(row)
+		err = ws.createRow(row)
 		if err != nil {
-			return errors.Wrapf(err, "failed to create row %s", spew.Sprint(row))
+			return errors.Wrapf(err, "failed to create row %d", row.index+1)
 		}
 	}
 
@@ -248,22 +767,51 @@ func (ws *Worksheet) CommitRows() error {
 	return nil
 }
 
-// Commit commits the worksheet.
+// Commit commits the worksheet and its overflow worksheets.
 func (ws *Worksheet) Commit() error {
+	return ws.CommitContext(context.Background())
+}
+
+// CommitContext is just like Commit but stops when the context is done, in which case the whole workbook is aborted as
+// by CommitRowsContext.
+func (ws *Worksheet) CommitContext(ctx context.Context) error {
+	err := ws.commit(ctx)
+	if err != nil && ctx.Err() != nil {
+		ws.workbook.abort()
+		return ctx.Err()
+	}
+	return ws.abortedError(err, "can't commit the worksheet")
+}
+
+func (ws *Worksheet) commit(ctx context.Context) error {
 	if ws.committed {
-		return errors.New("can't commit an already committed worksheet")
+		return errors.Wrap(ErrCommitted, "can't commit the worksheet")
+	}
+
+	if ws.workbook.isAborted() {
+		return errors.Wrap(ErrAborted, "can't commit the worksheet")
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
 	if len(ws.pendingRows) > 0 {
-		return errors.New("can't commit worksheet if there are still pending rows to be committed")
+		return errors.Wrap(ErrPendingRows, "can't commit the worksheet")
 	}
 
-	err := ws.end()
+	err := ws.end(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to end the worksheet")
 	}
 
+	ws.workbook.mu.Lock()
 	ws.committed = true
+	ws.workbook.mu.Unlock()
+
+	if ws.next != nil {
+		return ws.next.commit(ctx)
+	}
 
 	return nil
 }
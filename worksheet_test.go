@@ -0,0 +1,238 @@
+package xlsx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Worksheet_Commit_ShouldWriteAutoFilter_WhenAutoFilterIsEnabled(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-3.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:         "Data",
+		FreezeHeader: true,
+		AutoFilter:   true,
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "id", Value: "ID"},
+		&WorksheetColumn{Key: "name", Value: "Name"},
+		&WorksheetColumn{Key: "amount", Value: "Amount"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	for i := 0; i < 4; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("id")
+		cell.Value = i
+	}
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<autoFilter ref="A1:C5"/>`) {
+		t.Errorf("worksheet is missing the auto filter, found: %s", content)
+	}
+	if !strings.Contains(string(content), `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`) {
+		t.Errorf("worksheet is missing the frozen header, found: %s", content)
+	}
+
+	names := wb.definedNames()
+	if len(names) != 1 || names[0].name != "_xlnm._FilterDatabase" || names[0].refersTo != "'Data'!$A$1:$C$5" {
+		t.Errorf("unexpected defined names, found: %v", names)
+		return
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
+
+func Test_Worksheet_Commit_ShouldWriteOutline_WhenRowsAndColumnsAreGrouped(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet-7.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{
+		Name:             "Costs",
+		SummaryRowsAbove: true,
+		MaxOutlineLevel:  2,
+	})
+
+	err := ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "item", Value: "Item"},
+		&WorksheetColumn{Key: "q1", Value: "Q1", OutlineLevel: 1},
+		&WorksheetColumn{Key: "q2", Value: "Q2", OutlineLevel: 1},
+		&WorksheetColumn{Key: "total", Value: "Total"},
+	})
+	if err != nil {
+		t.Errorf("failed to define columns: %v", err)
+		return
+	}
+
+	parent, _ := ws.AddRowWithOptions(&RowOptions{Collapsed: true})
+	cell, _ := parent.AddCellWithKey("item")
+	cell.Value = "Travel"
+	child, err := parent.AddChildRow()
+	if err != nil {
+		t.Errorf("failed to add a child row: %v", err)
+		return
+	}
+	cell, _ = child.AddCellWithKey("item")
+	cell.Value = "Flights"
+	grandchild, err := child.AddChildRow()
+	if err != nil {
+		t.Errorf("failed to add a child row: %v", err)
+		return
+	}
+	cell, _ = grandchild.AddCellWithKey("item")
+	cell.Value = "Upgrades"
+
+	_, err = grandchild.AddChildRow()
+	if err == nil || !strings.Contains(err.Error(), "MaxOutlineLevel") {
+		t.Errorf("expected an error when grouping a row deeper than the max outline level, found: %v", err)
+	}
+
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	for _, expected := range []string{
+		`<sheetPr><outlinePr summaryBelow="0"/></sheetPr>`,
+		`<sheetFormatPr defaultRowHeight="15" outlineLevelRow="2" outlineLevelCol="1" x14ac:dyDescent="0.25"/>`,
+		`<cols><col min="2" max="2" width="9.140625" outlineLevel="1"/><col min="3" max="3" width="9.140625" outlineLevel="1"/></cols>`,
+		`<row r="2" collapsed="1">`,
+		`<row r="3" hidden="1" outlineLevel="1">`,
+		`<row r="4" hidden="1" outlineLevel="2">`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("worksheet is missing %s, found: %s", expected, content)
+		}
+	}
+
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+	}
+}
+
+func Test_Worksheet_Commit_ShouldWriteSparseRows_WhenCellsAndRowsArePositioned(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = "a"
+	cell, _ = row.SetCellByRef("F")
+	cell.Value = "f"
+	cell, _ = row.AddCell()
+	cell.Value = nil
+
+	_, err := row.SetCell(2)
+	if !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("expected ErrOutOfOrder when adding a cell before the last one, found: %v", err)
+	}
+
+	row, err = ws.AddRowAt(9)
+	if err != nil {
+		t.Errorf("failed to add the row: %v", err)
+		return
+	}
+	cell, _ = row.SetCellByRef("b")
+	cell.Value = "b"
+
+	_, err = ws.AddRowAt(5)
+	if !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("expected ErrOutOfOrder when adding a row before the last one, found: %v", err)
+	}
+
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	expected := `<sheetData><row r="1"><c r="A1" t="str"><v>a</v></c><c r="F1" t="str"><v>f</v></c></row><row r="10"><c r="B10" t="str"><v>b</v></c></row></sheetData>`
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("unexpected sheet data, found: %s", content)
+	}
+}
+
+func Test_Worksheet_CommitRows_ShouldStartAgain_WhenHeadFails(t *testing.T) {
+	wb := NewWorkbook("./spreadsheet.xlsx")
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.options.TabColor = "zzz"
+
+	row, _ := ws.AddRow()
+	cell, _ := row.AddCell()
+	cell.Value = "a"
+	err := ws.CommitRows()
+	if err == nil {
+		t.Error("expected an error when the head can't be written")
+	}
+	if ws.started {
+		t.Error("expected the worksheet not to be started")
+	}
+
+	ws.options.TabColor = ""
+	row, _ = ws.AddRow()
+	cell, _ = row.AddCell()
+	cell.Value = "b"
+	err = ws.CommitRows()
+	if err != nil {
+		t.Errorf("failed to commit rows: %v", err)
+		return
+	}
+	err = ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+
+	content, err := ioutil.ReadFile(ws.filePath)
+	if err != nil {
+		t.Errorf("failed to read the worksheet file: %v", err)
+		return
+	}
+	if !strings.Contains(string(content), `<sheetView tabSelected="0" workbookViewId="0"/></sheetViews>`) ||
+		!strings.Contains(string(content), `<sheetData><row r="1"><c r="A1" t="str"><v>a</v></c></row><row r="2">`) {
+		t.Errorf("expected the head to be written, found: %s", content)
+	}
+}
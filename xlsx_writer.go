@@ -2,8 +2,15 @@ package xlsx
 
 // NewWorkbook creates a new workbook, which is the base for every XLSX file.
 func NewWorkbook(filePath string) *Workbook {
+	return NewWorkbookWithOptions(filePath, &WorkbookOptions{})
+}
+
+// NewWorkbookWithOptions is just like NewWorkbook but takes options that apply to the whole workbook.
+func NewWorkbookWithOptions(filePath string, opts *WorkbookOptions) *Workbook {
 	return &Workbook{
 		FilePath: filePath,
+		options:  *opts,
 		tempDirs: &workbookTempDirs{},
+		styles:   newStyleSheet(),
 	}
 }
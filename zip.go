@@ -0,0 +1,232 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"context"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// zipDataDescriptor is the flag of the entries whose sizes are written after their data.
+const zipDataDescriptor = 0x8
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	err := r.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// zipOptions controls how the files are compressed.
+type zipOptions struct {
+	level   int
+	workers int
+}
+
+func newZipOptions(opts WorkbookOptions) (zipOptions, error) {
+	z := zipOptions{level: opts.CompressionLevel, workers: opts.CompressionWorkers}
+	if opts.NoCompression {
+		z.level = flate.NoCompression
+	} else if z.level == 0 {
+		z.level = flate.DefaultCompression
+	}
+	if z.level < flate.HuffmanOnly || z.level > flate.BestCompression {
+		return z, errors.Errorf("invalid compression level %d", opts.CompressionLevel)
+	}
+	if z.workers <= 0 {
+		z.workers = runtime.GOMAXPROCS(0)
+	}
+	return z, nil
+}
+
+// zipEntry is a file compressed ahead of being added to the zip file.
+type zipEntry struct {
+	name       string
+	filePath   string
+	info       os.FileInfo
+	header     *zip.FileHeader
+	compressed string
+	// done receives the outcome of the compression.
+	done chan error
+}
+
+// zipDir writes every file of a directory as a zip file, named after their path relative to the directory. The files
+// are compressed concurrently to separate files, which are then copied as they are to the zip file, in order.
+func zipDir(ctx context.Context, dir string, out io.Writer, opts zipOptions) error {
+	var entries []*zipEntry
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &zipEntry{
+			name:     filepath.ToSlash(name),
+			filePath: p,
+			info:     info,
+			done:     make(chan error, 1),
+		})
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the files of %s", dir)
+	}
+
+	partsDir, err := ioutil.TempDir("", "xlsx-parts-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory for the compressed files")
+	}
+	defer os.RemoveAll(partsDir)
+
+	// The workers are stopped, and waited for, before the compressed files are removed.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan *zipEntry)
+	for i := 0; i < opts.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				e.done <- compressFile(ctx, e, opts.level)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := 0; i < len(entries); i++ {
+			entries[i].compressed = filepath.Join(partsDir, strconv.Itoa(i))
+			select {
+			case jobs <- entries[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	w := zip.NewWriter(out)
+	for i := 0; i < len(entries); i++ {
+		select {
+		case err = <-entries[i].done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		err = copyCompressedFile(ctx, w, entries[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to close the zip writer")
+	}
+	return nil
+}
+
+// compressFile deflates a file to the compressed file of the entry and sets the header it's added with.
+func compressFile(ctx context.Context, e *zipEntry, level int) error {
+	src, err := os.Open(e.filePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", e.filePath)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(e.compressed)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", e.compressed)
+	}
+	defer dst.Close()
+
+	fw, err := flate.NewWriter(dst, level)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compress %s", e.name)
+	}
+	crc := crc32.NewIEEE()
+	n, err := io.Copy(io.MultiWriter(fw, crc), &contextReader{ctx: ctx, r: src})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.Wrapf(err, "failed to compress %s", e.name)
+	}
+	err = fw.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to compress %s", e.name)
+	}
+	size, err := dst.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the size of %s", e.compressed)
+	}
+
+	e.header = newZipHeader(e.name, e.info.ModTime(), crc.Sum32(), uint64(size), uint64(n))
+	return dst.Close()
+}
+
+// newZipHeader returns the header of a deflated entry. The sizes of the entries over 4 GiB are written to a ZIP64 data
+// descriptor following their data, as the zip writer does for the entries it compresses itself, the central directory
+// and its end get ZIP64 records as needed.
+func newZipHeader(name string, modified time.Time, crc uint32, compressedSize, size uint64) *zip.FileHeader {
+	h := &zip.FileHeader{
+		Name:               name,
+		Method:             zip.Deflate,
+		Modified:           modified,
+		CRC32:              crc,
+		CompressedSize64:   compressedSize,
+		UncompressedSize64: size,
+	}
+	if compressedSize > math.MaxUint32 || size > math.MaxUint32 {
+		h.Flags |= zipDataDescriptor
+	}
+	return h
+}
+
+// copyCompressedFile adds the compressed file of an entry to the zip file as it is.
+func copyCompressedFile(ctx context.Context, w *zip.Writer, e *zipEntry) error {
+	f, err := os.Open(e.compressed)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", e.compressed)
+	}
+	defer f.Close()
+
+	entry, err := w.CreateRaw(e.header)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %s to the zip file", e.name)
+	}
+	_, err = io.Copy(entry, &contextReader{ctx: ctx, r: f})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.Wrapf(err, "failed to copy %s to the zip file", e.name)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRawEntries adds the same compressed data to a zip file under each header, the data doesn't have to match the
+// sizes of the headers as the entries are never decompressed.
+func writeRawEntries(t *testing.T, headers []*zip.FileHeader) ([]byte, *zip.Reader) {
+	dir, _ := ioutil.TempDir("", "xlsx-zip-")
+	defer os.RemoveAll(dir)
+	compressed := path.Join(dir, "compressed")
+	ioutil.WriteFile(compressed, []byte("compressed data"), 0666)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for i := 0; i < len(headers); i++ {
+		err := copyCompressedFile(context.Background(), w, &zipEntry{name: headers[i].Name, compressed: compressed, header: headers[i]})
+		if err != nil {
+			t.Fatalf("failed to copy the compressed file: %v", err)
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		t.Fatalf("failed to close the zip writer: %v", err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read the zip file: %v", err)
+	}
+	return buf.Bytes(), r
+}
+
+func Test_copyCompressedFile_ShouldWriteZIP64DataDescriptor_WhenEntryIsOver4GiB(t *testing.T) {
+	size := uint64(5 << 30)
+	data, r := writeRawEntries(t, []*zip.FileHeader{
+		newZipHeader("xl/worksheets/sheet1.xml", time.Now(), 0, 15, size),
+		newZipHeader("xl/styles.xml", time.Now(), 0, 15, 100),
+	})
+
+	sheet := r.File[0]
+	if sheet.UncompressedSize64 != size || sheet.UncompressedSize != math.MaxUint32 {
+		t.Errorf("expected the worksheet sizes to be in a ZIP64 record, found %d and %d", sheet.UncompressedSize64, sheet.UncompressedSize)
+	}
+	if sheet.Flags&zipDataDescriptor == 0 {
+		t.Error("expected the worksheet sizes to be written after its data")
+	}
+	if r.File[1].Flags&zipDataDescriptor != 0 || r.File[1].UncompressedSize != 100 {
+		t.Errorf("expected the styles sizes to be in the local header, found: %+v", r.File[1].FileHeader)
+	}
+
+	// The data descriptor of an entry over 4 GiB holds its signature, CRC-32 and 64-bit sizes.
+	offset, err := sheet.DataOffset()
+	if err != nil {
+		t.Errorf("failed to find the worksheet data: %v", err)
+		return
+	}
+	descriptor := data[offset+15 : offset+15+24]
+	if binary.LittleEndian.Uint32(descriptor) != 0x08074b50 || binary.LittleEndian.Uint64(descriptor[16:]) != size {
+		t.Errorf("unexpected data descriptor: %x", descriptor)
+	}
+}
+
+func Test_copyCompressedFile_ShouldWriteZIP64End_WhenThereAreOver65535Entries(t *testing.T) {
+	headers := make([]*zip.FileHeader, 70000)
+	for i := 0; i < len(headers); i++ {
+		headers[i] = newZipHeader(fmt.Sprintf("xl/media/%d", i), time.Now(), 0, 15, 15)
+	}
+	_, r := writeRawEntries(t, headers)
+
+	if len(r.File) != len(headers) {
+		t.Errorf("expected %d entries, found %d", len(headers), len(r.File))
+	}
+}
+
+func Test_Workbook_Commit_ShouldWriteZIP64_WhenWorksheetIsOver4GiB(t *testing.T) {
+	if os.Getenv("XLSX_LARGE_TESTS") == "" {
+		t.Skip("writes more than 4 GiB of temporary files, set XLSX_LARGE_TESTS to run it")
+	}
+
+	filePath := path.Join(os.TempDir(), "xlsx-zip64.xlsx")
+	defer os.Remove(filePath)
+	wb := NewWorkbookWithOptions(filePath, &WorkbookOptions{CompressionLevel: flate.BestSpeed})
+	defer func() { os.RemoveAll(wb.tempRootDir) }()
+	ws, _ := wb.AddWorksheet(&WorksheetOptions{Name: "Data"})
+	ws.DefineColumns([]*WorksheetColumn{
+		&WorksheetColumn{Key: "a", Value: "A"},
+		&WorksheetColumn{Key: "b", Value: "B"},
+	})
+
+	// Every row takes about 64 KiB, 70000 of them go past 4 GiB.
+	value := strings.Repeat("0123456789abcdef", maxCellLength/16)
+	for i := 0; i < 70000; i++ {
+		row, _ := ws.AddRow()
+		cell, _ := row.AddCellWithKey("a")
+		cell.Value = value
+		cell, _ = row.AddCellWithKey("b")
+		cell.Value = value
+		if (i+1)%1000 == 0 {
+			err := ws.CommitRows()
+			if err != nil {
+				t.Errorf("failed to commit rows: %v", err)
+				return
+			}
+		}
+	}
+	err := ws.Commit()
+	if err != nil {
+		t.Errorf("failed to commit worksheet: %v", err)
+		return
+	}
+	err = wb.Commit()
+	if err != nil {
+		t.Errorf("failed to commit workbook: %v", err)
+		return
+	}
+
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		t.Errorf("failed to open the zip file: %v", err)
+		return
+	}
+	defer r.Close()
+
+	var sheet *zip.File
+	for _, f := range r.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = f
+		}
+	}
+	if sheet == nil {
+		t.Error("the worksheet is missing from the zip file")
+		return
+	}
+	if sheet.UncompressedSize64 <= math.MaxUint32 || sheet.UncompressedSize != math.MaxUint32 {
+		t.Errorf("expected the worksheet sizes to be in a ZIP64 record, found %d and %d", sheet.UncompressedSize64, sheet.UncompressedSize)
+	}
+	if sheet.Flags&zipDataDescriptor == 0 {
+		t.Error("expected the worksheet sizes to be written after its data")
+	}
+
+	rc, err := sheet.Open()
+	if err != nil {
+		t.Errorf("failed to open the worksheet: %v", err)
+		return
+	}
+	defer rc.Close()
+	// Reading the whole entry has the reader check its size and CRC-32 against the data descriptor.
+	n, err := io.Copy(ioutil.Discard, rc)
+	if err != nil {
+		t.Errorf("failed to decompress the worksheet: %v", err)
+	}
+	if uint64(n) != sheet.UncompressedSize64 {
+		t.Errorf("expected %d bytes, found %d", sheet.UncompressedSize64, n)
+	}
+
+	// The data descriptor of an entry over 4 GiB holds 64-bit sizes.
+	offset, err := sheet.DataOffset()
+	if err != nil {
+		t.Errorf("failed to find the worksheet data: %v", err)
+		return
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		t.Errorf("failed to open the zip file: %v", err)
+		return
+	}
+	defer f.Close()
+	descriptor := make([]byte, 24)
+	_, err = f.ReadAt(descriptor, offset+int64(sheet.CompressedSize64))
+	if err != nil {
+		t.Errorf("failed to read the data descriptor: %v", err)
+		return
+	}
+	if binary.LittleEndian.Uint64(descriptor[16:]) != sheet.UncompressedSize64 {
+		t.Errorf("unexpected data descriptor: %x", descriptor)
+	}
+}